@@ -0,0 +1,205 @@
+// Statements as parsed, before they are compiled into requests
+package dsql
+
+// A Stmt is one parsed statement: *SelectStmt, *InsertStmt, *UpdateStmt,
+// *DeleteStmt, *CreateTableStmt, *DropTableStmt, *AlterTableStmt,
+// *CreateIndexStmt, *DropIndexStmt, *ShowTablesStmt, *ShowCreateTableStmt,
+// *DescribeStmt or *TruncateStmt
+type Stmt interface {
+	stmt()
+}
+
+// SELECT columns FROM [schema.]table [WHERE ...] [ORDER BY column [ASC|DESC]]
+// [LIMIT n]
+type SelectStmt struct {
+	Columns   []string // nil for *
+	Schema    string   // only information_schema, "" for DynamoDB tables
+	Table     string
+	Where     Expr
+	OrderBy   string
+	Direction string // "asc", "desc" or "" (descending)
+	Limit     int
+}
+
+// INSERT INTO table (columns) VALUES (values)
+type InsertStmt struct {
+	Table   string
+	Columns []string
+	Values  []Attribute
+}
+
+// UPDATE table SET name = value, ... WHERE ...
+type UpdateStmt struct {
+	Table string
+	Set   []Expression
+	Where Expr
+}
+
+// DELETE FROM table WHERE ...
+type DeleteStmt struct {
+	Table string
+	Where Expr
+}
+
+// CREATE TABLE [IF NOT EXISTS] table (definitions, indexes)
+// [WITH (name = value, ...)] [WAIT]
+type CreateTableStmt struct {
+	Table       string
+	Definitions []Definition
+	Indexes     []IndexDefinition
+	Options     []Option
+	IfNotExists bool
+	Wait        bool // until the table is active
+}
+
+// DROP TABLE [IF EXISTS] table [WAIT]
+type DropTableStmt struct {
+	Table    string
+	IfExists bool
+	Wait     bool // until the table is gone
+}
+
+// SHOW TABLES
+type ShowTablesStmt struct{}
+
+// SHOW CREATE TABLE table
+type ShowCreateTableStmt struct {
+	Table string
+}
+
+// DESCRIBE [TABLE] table
+type DescribeStmt struct {
+	Table string
+}
+
+// TRUNCATE [TABLE] table
+type TruncateStmt struct {
+	Table string
+}
+
+// ALTER TABLE table SET (name = value, ...), INDEX name (name = value, ...),
+// TTL ON attribute | TTL OFF
+type AlterTableStmt struct {
+	Table   string
+	Options []Option
+	Indexes []AlterIndex
+	TTL     string // attribute of TTL ON
+	TTLOff  bool
+}
+
+// the options changed on one global index
+type AlterIndex struct {
+	Name    string
+	Options []Option
+}
+
+// name = value in WITH and SET lists, lower cased like keywords except a ttl
+// attribute, like read = 10 or billing = pay_per_request
+type Option struct {
+	Name  string
+	Value string
+}
+
+// CREATE INDEX name ON table (key [type] HASH|RANGE, ...)
+// [WITH (PROJECTION = ..., READ = 1, WRITE = 1)] [WAIT]
+type CreateIndexStmt struct {
+	Name       string
+	Table      string
+	Keys       []Definition // the type is optional
+	Projection string
+	Include    []string
+	Read       int
+	Write      int
+	Wait       bool // until the index is active
+}
+
+// DROP INDEX name ON table [WAIT]
+type DropIndexStmt struct {
+	Name  string
+	Table string
+	Wait  bool // until the index is gone
+}
+
+func (*SelectStmt) stmt()          {}
+func (*InsertStmt) stmt()          {}
+func (*UpdateStmt) stmt()          {}
+func (*DeleteStmt) stmt()          {}
+func (*CreateTableStmt) stmt()     {}
+func (*DropTableStmt) stmt()       {}
+func (*AlterTableStmt) stmt()      {}
+func (*CreateIndexStmt) stmt()     {}
+func (*DropIndexStmt) stmt()       {}
+func (*ShowTablesStmt) stmt()      {}
+func (*ShowCreateTableStmt) stmt() {}
+func (*DescribeStmt) stmt()        {}
+func (*TruncateStmt) stmt()        {}
+
+// An Expr is a condition: an Expression or a Logical joining two
+type Expr interface {
+	expr()
+}
+
+// A comparison such as id = 1, name LIKE "a" or n BETWEEN (1, 5), also
+// used for the name = value pairs of SET and WITH
+type Expression struct {
+	Identifier string
+	Operator   string
+	Value      Attribute
+	Between    Attribute
+}
+
+func (exp *Expression) Attribute() (a Attribute) {
+	return exp.Value
+}
+
+func (exp *Expression) BetweenAttribute() (a Attribute) {
+	return exp.Between
+}
+
+// Left AND Right or Left OR Right
+type Logical struct {
+	Operator string
+	Left     Expr
+	Right    Expr
+}
+
+func (Expression) expr() {}
+func (Logical) expr()    {}
+
+// a column in CREATE TABLE: name type [HASH|RANGE]
+type Definition struct {
+	Identifier string
+	Type       string
+	Constraint string
+}
+
+// flatten returns the comparisons in e from left to right, each with the
+// "and" or "or" joining it to the previous one ("" for the first)
+func flatten(e Expr) (conjs []string, exps []Expression) {
+	var walk func(e Expr, conj string)
+	walk = func(e Expr, conj string) {
+		switch e := e.(type) {
+		case Expression:
+			conjs = append(conjs, conj)
+			exps = append(exps, e)
+		case Logical:
+			walk(e.Left, conj)
+			walk(e.Right, e.Operator)
+		}
+	}
+	walk(e, "")
+	return conjs, exps
+}
+
+// a secondary index in CREATE TABLE:
+// [LOCAL|GLOBAL] INDEX name WITH (HASH = a, RANGE = b, PROJECTION = ...)
+type IndexDefinition struct {
+	Name       string
+	Scope      string // "local", "global" or "" to go by the hash key
+	Hash       string
+	Range      string
+	Projection string   // "all", "keys_only", "include" or "" (all)
+	Include    []string // non-key attributes of an "include" projection
+	Read       int
+	Write      int
+}
@@ -1,21 +1,320 @@
 package dsql
 
-import "strconv"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
+// A DynamoDB attribute value; only the field matching the type is set
 type Attribute struct {
-	S string `json:",omitempty"`
-	N string `json:",omitempty"`
+	S    *string
+	N    string
+	B    []byte
+	SS   []string
+	NS   []string
+	BS   [][]byte
+	BOOL *bool
+	NULL bool
+	L    []Attribute
+	M    map[string]Attribute
 }
 
-func (a Attribute) Value() interface{} {
-	if a.S != "" {
-		return []byte(a.S)
+// NewAttribute encodes a Go value as the closest DynamoDB type
+func NewAttribute(v interface{}) (a Attribute, err error) {
+	switch v := v.(type) {
+	case nil:
+		a.NULL = true
+		return a, nil
+	case Attribute:
+		return v, nil
+	case bool:
+		a.BOOL = &v
+		return a, nil
+	case string:
+		a.S = &v
+		return a, nil
+	case []byte:
+		a.B = v
+		return a, nil
+	case time.Time:
+		s := v.Format(time.RFC3339Nano)
+		a.S = &s
+		return a, nil
+	case Decimal:
+		if _, ok := v.Rat(); !ok {
+			return a, fmt.Errorf("dsql: %q is not a number", string(v))
+		}
+		a.N = string(v)
+		return a, nil
+	case json.Number:
+		a.N = string(v)
+		return a, nil
+	case *big.Int:
+		a.N = v.String()
+		return a, nil
+	case *big.Rat:
+		a.N, err = formatRat(v)
+		return a, err
 	}
 
-	if a.N != "" {
-		i, _ := strconv.Atoi(a.N)
-		return i
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		a.N, err = number(rv)
+	case reflect.Slice:
+		a, err = newList(rv)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return a, fmt.Errorf("dsql: unsupported map key type %s", rv.Type().Key())
+		}
+		a.M = map[string]Attribute{}
+		for _, k := range rv.MapKeys() {
+			a.M[k.String()], err = NewAttribute(rv.MapIndex(k).Interface())
+			if err != nil {
+				return a, err
+			}
+		}
+	default:
+		err = fmt.Errorf("dsql: unsupported type %T", v)
 	}
+	return a, err
+}
+
+// slices of strings, numbers and binaries become sets, anything else a list
+func newList(rv reflect.Value) (a Attribute, err error) {
+	elems := make([]Attribute, rv.Len())
+	for i := range elems {
+		elems[i], err = NewAttribute(rv.Index(i).Interface())
+		if err != nil {
+			return a, err
+		}
+	}
+
+	kind := rv.Type().Elem().Kind()
+	set := kind == reflect.String || kind == reflect.Slice && rv.Type().Elem().Elem().Kind() == reflect.Uint8 ||
+		kind >= reflect.Int && kind <= reflect.Float64 && kind != reflect.Uint8 && kind != reflect.Uintptr
+	if set && len(elems) == 0 {
+		return a, fmt.Errorf("dsql: empty %s can't be stored as a set, use NULL instead", rv.Type())
+	}
+
+	switch kind {
+	case reflect.String:
+		a.SS = []string{}
+		for _, e := range elems {
+			a.SS = append(a.SS, *e.S)
+		}
+	case reflect.Slice:
+		if rv.Type().Elem().Elem().Kind() != reflect.Uint8 {
+			a.L = elems
+			break
+		}
+		a.BS = [][]byte{}
+		for _, e := range elems {
+			a.BS = append(a.BS, e.B)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		a.NS = []string{}
+		for _, e := range elems {
+			a.NS = append(a.NS, e.N)
+		}
+	default:
+		a.L = elems
+	}
+	return a, nil
+}
 
+func number(rv reflect.Value) (string, error) {
+	switch rv.Kind() {
+	case reflect.Float32:
+		return formatFloat(rv.Float(), 32)
+	case reflect.Float64:
+		return formatFloat(rv.Float(), 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return strconv.FormatInt(rv.Int(), 10), nil
+}
+
+// Type returns the DynamoDB type descriptor (S, N, B, SS, ...) or ""
+func (a Attribute) Type() string {
+	switch {
+	case a.S != nil:
+		return "S"
+	case a.N != "":
+		return "N"
+	case a.B != nil:
+		return "B"
+	case a.SS != nil:
+		return "SS"
+	case a.NS != nil:
+		return "NS"
+	case a.BS != nil:
+		return "BS"
+	case a.BOOL != nil:
+		return "BOOL"
+	case a.NULL:
+		return "NULL"
+	case a.L != nil:
+		return "L"
+	case a.M != nil:
+		return "M"
+	}
+	return ""
+}
+
+// only the set field is written, so empty lists and maps survive; DynamoDB
+// has no empty sets
+func (a Attribute) MarshalJSON() ([]byte, error) {
+	var v interface{}
+
+	switch t := a.Type(); {
+	case t == "SS" && len(a.SS) == 0, t == "NS" && len(a.NS) == 0, t == "BS" && len(a.BS) == 0:
+		return nil, fmt.Errorf("dsql: %s set is empty", t)
+	}
+
+	switch a.Type() {
+	case "S":
+		v = *a.S
+	case "N":
+		v = a.N
+	case "B":
+		v = a.B
+	case "SS":
+		v = a.SS
+	case "NS":
+		v = a.NS
+	case "BS":
+		v = a.BS
+	case "BOOL":
+		v = *a.BOOL
+	case "NULL":
+		v = true
+	case "L":
+		v = a.L
+	case "M":
+		v = a.M
+	default:
+		return nil, fmt.Errorf("dsql: attribute has no value")
+	}
+
+	return json.Marshal(map[string]interface{}{a.Type(): v})
+}
+
+// Value converts to a driver.Value: numbers are int64 when they fit and
+// decimal strings otherwise, sets, lists and maps are returned as JSON
+func (a Attribute) Value() interface{} {
+	switch a.Type() {
+	case "S":
+		return append([]byte{}, *a.S...) // not nil when empty
+	case "N":
+		return parseNumber(a.N)
+	case "B":
+		return a.B
+	case "BOOL":
+		return *a.BOOL
+	case "SS", "NS", "BS", "L", "M":
+		b, _ := json.Marshal(a.plain())
+		return b
+	}
+	return nil
+}
+
+// plain converts to the natural Go representation
+func (a Attribute) plain() interface{} {
+	switch a.Type() {
+	case "S":
+		return *a.S
+	case "N":
+		return json.Number(a.N)
+	case "B":
+		return a.B
+	case "SS":
+		return a.SS
+	case "NS":
+		ns := make([]json.Number, len(a.NS))
+		for i, n := range a.NS {
+			ns[i] = json.Number(n)
+		}
+		return ns
+	case "BS":
+		return a.BS
+	case "BOOL":
+		return *a.BOOL
+	case "L":
+		l := make([]interface{}, len(a.L))
+		for i, e := range a.L {
+			l[i] = e.plain()
+		}
+		return l
+	case "M":
+		m := map[string]interface{}{}
+		for k, e := range a.M {
+			m[k] = e.plain()
+		}
+		return m
+	}
 	return nil
 }
+
+// literal renders the attribute in dsql syntax
+func (a Attribute) literal() string {
+	switch a.Type() {
+	case "S":
+		return quoteString(*a.S)
+	case "N":
+		return a.N
+	case "B":
+		return "x'" + hex.EncodeToString(a.B) + "'"
+	case "SS":
+		var elems []string
+		for _, s := range a.SS {
+			elems = append(elems, quoteString(s))
+		}
+		return "<<" + strings.Join(elems, ", ") + ">>"
+	case "NS":
+		return "<<" + strings.Join(a.NS, ", ") + ">>"
+	case "BS":
+		var elems []string
+		for _, b := range a.BS {
+			elems = append(elems, Attribute{B: b}.literal())
+		}
+		return "<<" + strings.Join(elems, ", ") + ">>"
+	case "BOOL":
+		if *a.BOOL {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "L":
+		var elems []string
+		for _, e := range a.L {
+			elems = append(elems, e.literal())
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case "M":
+		var keys, pairs []string
+		for k := range a.M {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			pairs = append(pairs, quoteString(k)+": "+a.M[k].literal())
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	return "NULL" // NULL, or no value at all, which MarshalJSON refuses
+}
+
+func quoteString(s string) string {
+	return strconv.Quote(s)
+}
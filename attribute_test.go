@@ -7,16 +7,183 @@ import (
 	"testing"
 )
 
+func stringp(s string) *string {
+	return &s
+}
+
 func TestAttributeMarshal(t *testing.T) {
-	s := Attribute{S: "a", N: ""}
+	s := Attribute{S: stringp("a"), N: ""}
 	b, _ := json.Marshal(s)
 	if !reflect.DeepEqual(b, []byte(`{"S":"a"}`)) {
 		t.Error("bad marshal", string(b))
 	}
 
-	n := Attribute{S: "", N: "1"}
+	n := Attribute{N: "1"}
 	b, _ = json.Marshal(n)
 	if !reflect.DeepEqual(b, []byte(`{"N":"1"}`)) {
 		t.Error("bad marshal", string(b))
 	}
 }
+
+func TestAttributeUnmarshal(t *testing.T) {
+	var item Item
+	err := json.Unmarshal([]byte(`{
+		"s": {"S": "a"},
+		"n": {"N": "1"},
+		"b": {"B": "AQI="},
+		"ss": {"SS": ["a", "b"]},
+		"ns": {"NS": ["1", "2"]},
+		"bs": {"BS": ["AQ=="]},
+		"bool": {"BOOL": true},
+		"null": {"NULL": true},
+		"l": {"L": [{"S": "a"}, {"N": "1"}]},
+		"m": {"M": {"k": {"N": "1"}}}
+	}`), &item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, expected := range map[string]string{
+		"s": "S", "n": "N", "b": "B", "ss": "SS", "ns": "NS", "bs": "BS",
+		"bool": "BOOL", "null": "NULL", "l": "L", "m": "M",
+	} {
+		if actual := item[k].Type(); actual != expected {
+			t.Error("expected ", expected)
+			t.Error("actual   ", actual)
+		}
+	}
+
+	if !reflect.DeepEqual(item["b"].B, []byte{1, 2}) {
+		t.Error("bad binary", item["b"].B)
+	}
+}
+
+func TestAttributeUnmarshalEmptyString(t *testing.T) {
+	var item Item
+	err := json.Unmarshal([]byte(`{"s": {"S": ""}, "l": {"L": [{"S": ""}]}}`), &item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item["s"].Type() != "S" || item["l"].L[0].Type() != "S" {
+		t.Error("actual   ", item)
+	}
+	if v := item["s"].Value(); !reflect.DeepEqual(v, []byte{}) {
+		t.Error("expected ", []byte{})
+		t.Error("actual   ", v)
+	}
+	if _, ok := item["missing"]; ok || item["missing"].Value() != nil {
+		t.Error("expected nil for a missing attribute")
+	}
+}
+
+func TestAttributeMarshalEmptyList(t *testing.T) {
+	b, _ := json.Marshal(Attribute{L: []Attribute{}})
+	if string(b) != `{"L":[]}` {
+		t.Error("bad marshal", string(b))
+	}
+
+	yes := true
+	b, _ = json.Marshal(Item{"a": Attribute{BOOL: &yes}, "b": Attribute{NULL: true}})
+	if string(b) != `{"a":{"BOOL":true},"b":{"NULL":true}}` {
+		t.Error("bad marshal", string(b))
+	}
+}
+
+func TestAttributeValue(t *testing.T) {
+	yes := true
+	cases := []struct {
+		attr     Attribute
+		expected interface{}
+	}{
+		{Attribute{S: stringp("a")}, []byte("a")},
+		{Attribute{B: []byte{1}}, []byte{1}},
+		{Attribute{BOOL: &yes}, true},
+		{Attribute{NULL: true}, nil},
+		{Attribute{SS: []string{"a", "b"}}, []byte(`["a","b"]`)},
+		{Attribute{NS: []string{"1", "2.5"}}, []byte(`[1,2.5]`)},
+		{Attribute{L: []Attribute{Attribute{S: stringp("a")}, Attribute{N: "1"}}}, []byte(`["a",1]`)},
+		{Attribute{M: map[string]Attribute{"k": Attribute{BOOL: &yes}}}, []byte(`{"k":true}`)},
+	}
+
+	for _, c := range cases {
+		actual := c.attr.Value()
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Error("expected ", c.expected)
+			t.Error("actual   ", actual)
+		}
+	}
+}
+
+func TestNewAttribute(t *testing.T) {
+	no := false
+	cases := []struct {
+		value    interface{}
+		expected Attribute
+	}{
+		{nil, Attribute{NULL: true}},
+		{false, Attribute{BOOL: &no}},
+		{"a", Attribute{S: stringp("a")}},
+		{"", Attribute{S: stringp("")}},
+		{int64(-2), Attribute{N: "-2"}},
+		{[]byte{1}, Attribute{B: []byte{1}}},
+		{[]string{"a"}, Attribute{SS: []string{"a"}}},
+		{[]int{1, 2}, Attribute{NS: []string{"1", "2"}}},
+		{[][]byte{[]byte{1}}, Attribute{BS: [][]byte{[]byte{1}}}},
+		{[]interface{}{"a", 1}, Attribute{L: []Attribute{Attribute{S: stringp("a")}, Attribute{N: "1"}}}},
+		{map[string]interface{}{"k": "v"}, Attribute{M: map[string]Attribute{"k": Attribute{S: stringp("v")}}}},
+	}
+
+	for _, c := range cases {
+		actual, err := NewAttribute(c.value)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Error("expected ", c.expected)
+			t.Error("actual   ", actual)
+		}
+	}
+
+	if _, err := NewAttribute(struct{}{}); err == nil {
+		t.Error("expected error for struct")
+	}
+
+	for _, empty := range []interface{}{[]string{}, []int{}, [][]byte{}} {
+		if _, err := NewAttribute(empty); err == nil {
+			t.Errorf("expected error for empty %T", empty)
+		}
+	}
+}
+
+func TestAttributeMarshalEmptyString(t *testing.T) {
+	a, _ := NewAttribute("")
+	b, err := json.Marshal(a)
+	if err != nil || string(b) != `{"S":""}` {
+		t.Error("bad marshal", string(b), err)
+	}
+	if a.literal() != `""` {
+		t.Error("actual   ", a.literal())
+	}
+
+	req, err := parseRequest(`INSERT INTO t (a) VALUES ("")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ = json.Marshal(req)
+	if string(b) != `{"TableName":"t","Item":{"a":{"S":""}}}` {
+		t.Error("bad marshal", string(b))
+	}
+
+	b, err = json.Marshal(Attribute{S: stringp("")})
+	if err != nil || string(b) != `{"S":""}` {
+		t.Error("bad marshal", string(b), err)
+	}
+
+	if _, err := json.Marshal(Attribute{}); err == nil {
+		t.Error("expected error for an attribute without a value")
+	}
+	if _, err := json.Marshal(Attribute{SS: []string{}}); err == nil {
+		t.Error("expected error for an empty set")
+	}
+}
@@ -0,0 +1,129 @@
+package dsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type BatchWriteItem struct {
+	RequestItems map[string][]WriteRequest
+}
+
+type WriteRequest struct {
+	DeleteRequest *DeleteRequest `json:",omitempty"`
+}
+
+type DeleteRequest struct {
+	Key Item
+}
+
+type BatchWriteItemResponse struct {
+	UnprocessedItems map[string][]WriteRequest
+}
+
+func (b BatchWriteItem) decode(body io.Reader) (*BatchWriteItemResponse, error) {
+	res := &BatchWriteItemResponse{}
+	if err := json.NewDecoder(body).Decode(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (b BatchWriteItem) Rows(body io.ReadCloser) (driver.Rows, error) {
+	return nil, nil
+}
+
+// limits of BatchWriteItem, and how many batches are in flight at once
+var (
+	batchSize    = 25
+	batchWorkers = 4
+	batchRetries = 8
+	retryDelay   = 50 * time.Millisecond // doubled after every retry
+)
+
+// deleteKeys deletes the items with the given keys from table in batches,
+// batchWorkers at a time, and returns how many were deleted
+func deleteKeys(ctx context.Context, cl Client, table string, keys []Item) (int64, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		deleted int64
+		failed  error
+	)
+	workers := make(chan bool, batchWorkers)
+
+	for len(keys) > 0 {
+		mu.Lock()
+		stop := failed != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+
+		n := batchSize
+		if len(keys) < n {
+			n = len(keys)
+		}
+		batch := keys[:n]
+		keys = keys[n:]
+
+		workers <- true
+		wg.Add(1)
+		go func() {
+			defer func() { <-workers; wg.Done() }()
+
+			n, err := deleteBatch(ctx, cl, table, batch)
+			mu.Lock()
+			defer mu.Unlock()
+			deleted += int64(n)
+			if failed == nil {
+				failed = err
+			}
+		}()
+	}
+
+	wg.Wait()
+	return deleted, failed
+}
+
+// deleteBatch sends the unprocessed part of a batch again, backing off,
+// until every item is deleted, batchRetries is used up or ctx is done
+func deleteBatch(ctx context.Context, cl Client, table string, keys []Item) (int, error) {
+	var pending []WriteRequest
+	for _, key := range keys {
+		pending = append(pending, WriteRequest{DeleteRequest: &DeleteRequest{key}})
+	}
+
+	delay := retryDelay
+	for retry := 0; ; retry++ {
+		req := BatchWriteItem{map[string][]WriteRequest{table: pending}}
+		body, err := cl.Post(req)
+		if err != nil {
+			return len(keys) - len(pending), err
+		}
+		res, err := req.decode(body)
+		body.Close()
+		if err != nil {
+			return len(keys) - len(pending), err
+		}
+
+		pending = res.UnprocessedItems[table]
+		if len(pending) == 0 {
+			return len(keys), nil
+		}
+		if retry == batchRetries {
+			return len(keys) - len(pending), fmt.Errorf("dsql: %d items of %s were not deleted, throughput exceeded", len(pending), table)
+		}
+		select {
+		case <-ctx.Done():
+			return len(keys) - len(pending), ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
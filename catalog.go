@@ -0,0 +1,147 @@
+// Remember table definitions so results can be described
+package dsql
+
+import "sync"
+
+// table layout, as reported by DescribeTable
+type TableDescription struct {
+	TableName              string
+	TableStatus            string `json:",omitempty"`
+	ItemCount              int64  `json:",omitempty"`
+	TableSizeBytes         int64  `json:",omitempty"`
+	AttributeDefinitions   []AttributeDefinition
+	KeySchema              []Schema
+	LocalSecondaryIndexes  []LocalSecondaryIndex  `json:",omitempty"`
+	GlobalSecondaryIndexes []GlobalSecondaryIndex `json:",omitempty"`
+	ProvisionedThroughput  *Throughput            `json:",omitempty"`
+	BillingModeSummary     *BillingModeSummary    `json:",omitempty"`
+	TableClassSummary      *TableClassSummary     `json:",omitempty"`
+	StreamSpecification    *StreamSpecification   `json:",omitempty"`
+}
+
+type BillingModeSummary struct {
+	BillingMode string
+}
+
+type TableClassSummary struct {
+	TableClass string
+}
+
+// definition returns the definition of the attribute called name, or nil
+func (t *TableDescription) definition(name string) *AttributeDefinition {
+	for i := range t.AttributeDefinitions {
+		if t.AttributeDefinitions[i].AttributeName == name {
+			return &t.AttributeDefinitions[i]
+		}
+	}
+	return nil
+}
+
+// index returns the global index called name, or nil
+func (t *TableDescription) index(name string) *GlobalSecondaryIndex {
+	for i := range t.GlobalSecondaryIndexes {
+		if t.GlobalSecondaryIndexes[i].IndexName == name {
+			return &t.GlobalSecondaryIndexes[i]
+		}
+	}
+	return nil
+}
+
+// the defined attributes, keys of the table first
+func (t *TableDescription) attributes() (names []string) {
+	for _, k := range t.keys() {
+		if t.definition(k) != nil {
+			names = append(names, k)
+		}
+	}
+	for _, def := range t.AttributeDefinitions {
+		if !contains(names, def.AttributeName) {
+			names = append(names, def.AttributeName)
+		}
+	}
+	return names
+}
+
+// lookup tells if key has exactly the key attributes of the table
+func (t *TableDescription) lookup(key Item) bool {
+	keys := t.keys()
+	if len(key) != len(keys) {
+		return false
+	}
+	for _, k := range keys {
+		if _, ok := key[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hash key first, then range
+func (t *TableDescription) keys() (keys []string) {
+	for _, kind := range []string{"HASH", "RANGE"} {
+		for _, s := range t.KeySchema {
+			if s.KeyType == kind {
+				keys = append(keys, s.AttributeName)
+			}
+		}
+	}
+	return keys
+}
+
+// tables known to a data source, filled as statements are executed
+type catalog struct {
+	mu     sync.Mutex
+	tables map[string]*TableDescription
+	ttls   map[string]string // attribute, empty when time to live is off
+}
+
+var catalogs = struct {
+	sync.Mutex
+	m map[string]*catalog
+}{m: map[string]*catalog{}}
+
+func getCatalog(name string) *catalog {
+	catalogs.Lock()
+	defer catalogs.Unlock()
+
+	c := catalogs.m[name]
+	if c == nil {
+		c = &catalog{tables: map[string]*TableDescription{}, ttls: map[string]string{}}
+		catalogs.m[name] = c
+	}
+	return c
+}
+
+// Table returns nil for tables this data source hasn't seen
+func (c *catalog) Table(name string) *TableDescription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tables[name]
+}
+
+func (c *catalog) Add(t *TableDescription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables[t.TableName] = t
+}
+
+func (c *catalog) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tables, name)
+	delete(c.ttls, name)
+}
+
+// TTL returns the time to live attribute of a table and whether it is known
+func (c *catalog) TTL(name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	attr, ok := c.ttls[name]
+	return attr, ok
+}
+
+func (c *catalog) SetTTL(name, attr string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttls[name] = attr
+}
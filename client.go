@@ -3,11 +3,14 @@ package dsql
 
 import (
 	"bytes"
+	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/bmizerany/aws4"
 	"io"
+	"strings"
 
 	"net/http"
 	"net/url"
@@ -29,6 +32,32 @@ type Client interface {
 	Post(Request) (io.ReadCloser, error)
 }
 
+// a ContextClient abandons requests when their context is done
+type ContextClient interface {
+	Client
+	PostContext(context.Context, Request) (io.ReadCloser, error)
+}
+
+// withContext returns a Client that fails once ctx is done
+func withContext(ctx context.Context, cl Client) Client {
+	return contextClient{ctx, cl}
+}
+
+type contextClient struct {
+	ctx context.Context
+	cl  Client
+}
+
+func (c contextClient) Post(req Request) (io.ReadCloser, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cc, ok := c.cl.(ContextClient); ok {
+		return cc.PostContext(c.ctx, req)
+	}
+	return c.cl.Post(req)
+}
+
 type DynamoClient struct {
 	keys    *aws4.Keys
 	service *aws4.Service
@@ -66,6 +95,10 @@ func NewDynamoClient(name string) *DynamoClient {
 }
 
 func (c *DynamoClient) Post(req Request) (io.ReadCloser, error) {
+	return c.PostContext(context.Background(), req)
+}
+
+func (c *DynamoClient) PostContext(ctx context.Context, req Request) (io.ReadCloser, error) {
 	b, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -76,6 +109,7 @@ func (c *DynamoClient) Post(req Request) (io.ReadCloser, error) {
 		return nil, err
 	}
 
+	request = request.WithContext(ctx)
 	request.Header.Set("Content-Type", "application/x-amz-json-1.0")
 	request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
 	request.Header.Set("X-Amz-Target", operation(req))
@@ -117,10 +151,44 @@ func (e *DynamoError) Error() string {
 	return fmt.Sprintf("dynamo: %s (%s)", e.Message, e.Type)
 }
 
+// isError tells if err is a DynamoError of the given type, like
+// ResourceNotFoundException
+func isError(err error, name string) bool {
+	var e *DynamoError
+	return errors.As(err, &e) && strings.HasSuffix(e.Type, "#"+name)
+}
+
 func operation(r Request) string {
+	var name string
 	switch r.(type) {
 	case *Query:
-		return "DynamoDB_20120810.Query"
+		name = "Query"
+	case PutItem:
+		name = "PutItem"
+	case UpdateItem:
+		name = "UpdateItem"
+	case DeleteItem:
+		name = "DeleteItem"
+	case CreateTable:
+		name = "CreateTable"
+	case DeleteTable:
+		name = "DeleteTable"
+	case UpdateTable:
+		name = "UpdateTable"
+	case DescribeTable:
+		name = "DescribeTable"
+	case *ListTables:
+		name = "ListTables"
+	case DescribeTimeToLive:
+		name = "DescribeTimeToLive"
+	case UpdateTimeToLive:
+		name = "UpdateTimeToLive"
+	case *Scan:
+		name = "Scan"
+	case BatchWriteItem:
+		name = "BatchWriteItem"
+	default:
+		return ""
 	}
-	return ""
+	return "DynamoDB_20120810." + name
 }
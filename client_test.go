@@ -40,3 +40,24 @@ func TestClientBadRequest(t *testing.T) {
 func TestClientOK(t *testing.T) {
 
 }
+
+func TestOperation(t *testing.T) {
+	cases := map[string]Request{
+		"DynamoDB_20120810.Query":              &Query{},
+		"DynamoDB_20120810.PutItem":            PutItem{},
+		"DynamoDB_20120810.UpdateTable":        UpdateTable{},
+		"DynamoDB_20120810.DescribeTable":      DescribeTable{},
+		"DynamoDB_20120810.ListTables":         &ListTables{},
+		"DynamoDB_20120810.DescribeTimeToLive": DescribeTimeToLive{},
+		"DynamoDB_20120810.UpdateTimeToLive":   UpdateTimeToLive{},
+		"DynamoDB_20120810.Scan":               &Scan{},
+		"DynamoDB_20120810.BatchWriteItem":     BatchWriteItem{},
+	}
+
+	for expected, req := range cases {
+		if actual := operation(req); actual != expected {
+			t.Error("expected ", expected)
+			t.Error("actual   ", actual)
+		}
+	}
+}
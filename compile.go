@@ -0,0 +1,306 @@
+// Turn parsed statements into DynamoDB requests
+package dsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Compile builds the request that carries out stmt
+func Compile(stmt Stmt) (Request, error) {
+	switch s := stmt.(type) {
+	case *SelectStmt:
+		return compileSelect(s)
+	case *InsertStmt:
+		return compileInsert(s)
+	case *UpdateStmt:
+		return compileUpdate(s)
+	case *DeleteStmt:
+		return compileDelete(s)
+	case *CreateTableStmt:
+		return compileCreateTable(s)
+	case *DropTableStmt:
+		return DeleteTable{TableName: s.Table, ifExists: s.IfExists, wait: s.Wait}, nil
+	case *AlterTableStmt:
+		return compileAlterTable(s)
+	case *ShowTablesStmt:
+		return &ListTables{}, nil
+	case *ShowCreateTableStmt:
+		return ShowCreateTable{s.Table}, nil
+	case *DescribeStmt:
+		return DescribeTable{s.Table}, nil
+	case *TruncateStmt:
+		return TruncateTable{s.Table}, nil
+	case *CreateIndexStmt:
+		return compileCreateIndex(s)
+	case *DropIndexStmt:
+		return UpdateTable{
+			TableName: s.Table,
+			GlobalSecondaryIndexUpdates: []GlobalSecondaryIndexUpdate{
+				GlobalSecondaryIndexUpdate{Delete: &DeleteGlobalSecondaryIndex{s.Name}},
+			},
+			wait: s.Wait,
+		}, nil
+	}
+	return nil, fmt.Errorf("dsql: can't compile %T", stmt)
+}
+
+func compileSelect(s *SelectStmt) (Request, error) {
+	if s.Schema != "" {
+		return compileSchemaQuery(s)
+	}
+
+	query := &Query{TableName: s.Table}
+	for _, col := range s.Columns {
+		query.AddColumn(col)
+	}
+
+	conjs, exps := flatten(s.Where)
+	for i, exp := range exps {
+		query.AddCondition(conjs[i], exp)
+	}
+
+	query.orderBy = s.OrderBy
+	query.ScanIndexForward = s.Direction == "asc" // default id desc
+	query.Limit = s.Limit
+	return query, nil
+}
+
+func compileInsert(s *InsertStmt) (Request, error) {
+	if len(s.Columns) != len(s.Values) {
+		return nil, fmt.Errorf("dsql: %d columns but %d values", len(s.Columns), len(s.Values))
+	}
+
+	item := Item{}
+	for i, col := range s.Columns {
+		item[col] = s.Values[i]
+	}
+	return PutItem{TableName: s.Table, Item: item}, nil
+}
+
+func compileUpdate(s *UpdateStmt) (Request, error) {
+	update := UpdateItem{TableName: s.Table}
+	for _, exp := range s.Set {
+		update.AddUpdate(exp)
+	}
+	if !isLookup(s.Where) {
+		return UpdateItems{update, s.Where}, nil
+	}
+
+	_, exps := flatten(s.Where)
+	for _, exp := range exps {
+		update.AddKey(exp)
+	}
+	return update, nil
+}
+
+func compileDelete(s *DeleteStmt) (Request, error) {
+	deleteItem := DeleteItem{TableName: s.Table}
+	if !isLookup(s.Where) {
+		return DeleteItems{deleteItem, s.Where}, nil
+	}
+
+	_, exps := flatten(s.Where)
+	for _, exp := range exps {
+		deleteItem.AddKey(exp)
+	}
+	return deleteItem, nil
+}
+
+// isLookup tells if where names a single item: comparisons with = joined by
+// AND, which become the key
+func isLookup(where Expr) bool {
+	conjs, exps := flatten(where)
+	for i, exp := range exps {
+		if exp.Operator != "=" || conjs[i] != "" && strings.ToLower(conjs[i]) != "and" {
+			return false
+		}
+	}
+	return true
+}
+
+func compileCreateTable(s *CreateTableStmt) (Request, error) {
+	create := CreateTable{TableName: s.Table, ifNotExists: s.IfNotExists, wait: s.Wait}
+	for _, def := range s.Definitions {
+		create.AddDefinition(def)
+	}
+
+	opts, err := compileOptions(s.Options, tableOptions)
+	if err != nil {
+		return nil, err
+	}
+	if t := opts.throughput; t != nil && (t.ReadCapacityUnits == 0 || t.WriteCapacityUnits == 0) {
+		return nil, fmt.Errorf("dsql: table %s needs both read and write throughput", s.Table)
+	}
+	create.BillingMode = opts.billing
+	create.ProvisionedThroughput = opts.throughput
+	create.TableClass = opts.class
+	if opts.stream != nil && opts.stream.StreamEnabled {
+		create.StreamSpecification = opts.stream
+	}
+	create.ttl = opts.ttl
+
+	for _, idx := range s.Indexes {
+		if err := create.AddIndex(idx); err != nil {
+			return nil, err
+		}
+	}
+	if err := create.validate(); err != nil {
+		return nil, err
+	}
+	return create, nil
+}
+
+// keys without a type are looked up when the statement is executed
+func compileCreateIndex(s *CreateIndexStmt) (Request, error) {
+	update := UpdateTable{TableName: s.Table, wait: s.Wait}
+	idx := GlobalSecondaryIndex{
+		IndexName:  s.Name,
+		Projection: Projection{ProjectionTypes[s.Projection], s.Include},
+	}
+
+	var hash, rng []Schema
+	for _, key := range s.Keys {
+		switch key.Type {
+		case "", "string", "number", "binary":
+		default:
+			return nil, fmt.Errorf("dsql: key %s can't be a %s", key.Identifier, key.Type)
+		}
+		if key.Type != "" {
+			update.AttributeDefinitions = append(
+				update.AttributeDefinitions,
+				AttributeDefinition{key.Identifier, DefinitionTypes[key.Type]},
+			)
+		}
+
+		schema := Schema{key.Identifier, strings.ToUpper(key.Constraint)}
+		if schema.KeyType == "HASH" {
+			hash = append(hash, schema)
+		} else {
+			rng = append(rng, schema)
+		}
+	}
+	if len(hash) != 1 || len(rng) > 1 {
+		return nil, fmt.Errorf("dsql: index %s needs one hash key and at most one range key", s.Name)
+	}
+	idx.KeySchema = append(hash, rng...)
+
+	if s.Read > 0 || s.Write > 0 {
+		idx.ProvisionedThroughput = &Throughput{s.Read, s.Write}
+	}
+
+	update.GlobalSecondaryIndexUpdates = []GlobalSecondaryIndexUpdate{
+		GlobalSecondaryIndexUpdate{Create: &idx},
+	}
+	return update, nil
+}
+
+// capacities left out of ALTER TABLE are kept, they are looked up when the
+// statement is executed
+func compileAlterTable(s *AlterTableStmt) (Request, error) {
+	opts, err := compileOptions(s.Options, alterOptions)
+	if err != nil {
+		return nil, err
+	}
+	if opts.billing == "PROVISIONED" {
+		if t := opts.throughput; t == nil || t.ReadCapacityUnits == 0 || t.WriteCapacityUnits == 0 {
+			return nil, fmt.Errorf("dsql: PROVISIONED billing needs both read and write throughput")
+		}
+	}
+
+	update := UpdateTable{
+		TableName:             s.Table,
+		BillingMode:           opts.billing,
+		ProvisionedThroughput: opts.throughput,
+		StreamSpecification:   opts.stream,
+		TableClass:            opts.class,
+	}
+	switch {
+	case s.TTL != "":
+		update.ttl = &TimeToLiveSpecification{true, s.TTL}
+	case s.TTLOff:
+		update.ttl = &TimeToLiveSpecification{Enabled: false}
+	}
+	for _, idx := range s.Indexes {
+		o, err := compileOptions(idx.Options, indexThroughput)
+		if err != nil {
+			return nil, err
+		}
+		if opts.billing == "PAY_PER_REQUEST" {
+			return nil, fmt.Errorf("dsql: index %s can't have throughput with PAY_PER_REQUEST billing", idx.Name)
+		}
+		update.GlobalSecondaryIndexUpdates = append(
+			update.GlobalSecondaryIndexUpdates,
+			GlobalSecondaryIndexUpdate{Update: &UpdateGlobalSecondaryIndex{idx.Name, *o.throughput}},
+		)
+	}
+	return update, nil
+}
+
+var (
+	tableOptions    = []string{"read", "write", "billing", "class", "stream", "ttl"}
+	alterOptions    = []string{"read", "write", "billing", "class", "stream"}
+	indexThroughput = []string{"read", "write"}
+)
+
+type settings struct {
+	throughput *Throughput // nil unless read or write is given
+	billing    string
+	class      string
+	stream     *StreamSpecification
+	ttl        string // attribute, as written
+}
+
+// compileOptions checks the names and values of allowed options; names are
+// case insensitive, other words like billing modes are upper cased
+func compileOptions(list []Option, allowed []string) (opts settings, err error) {
+	for _, opt := range list {
+		name := strings.ToLower(opt.Name)
+		if !contains(allowed, name) {
+			return opts, fmt.Errorf("dsql: unknown option %s (expected %s)", opt.Name, alternatives(allowed))
+		}
+
+		value := strings.ToUpper(opt.Value)
+		switch name {
+		case "read", "write":
+			units, err := strconv.Atoi(opt.Value)
+			if err != nil || units < 1 {
+				return opts, fmt.Errorf("dsql: %s throughput must be a positive integer", name)
+			}
+			if opts.throughput == nil {
+				opts.throughput = &Throughput{}
+			}
+			if name == "read" {
+				opts.throughput.ReadCapacityUnits = units
+			} else {
+				opts.throughput.WriteCapacityUnits = units
+			}
+		case "billing":
+			if !contains(BillingModes, value) {
+				return opts, fmt.Errorf("dsql: billing must be %s", alternatives(BillingModes))
+			}
+			opts.billing = value
+		case "class":
+			if !contains(TableClasses, value) {
+				return opts, fmt.Errorf("dsql: table class must be %s", alternatives(TableClasses))
+			}
+			opts.class = value
+		case "stream":
+			opts.stream = &StreamSpecification{StreamEnabled: value != "OFF"}
+			if value != "OFF" {
+				if !contains(StreamViewTypes, value) {
+					return opts, fmt.Errorf("dsql: stream must be OFF or %s", alternatives(StreamViewTypes))
+				}
+				opts.stream.StreamViewType = value
+			}
+		case "ttl":
+			opts.ttl = opt.Value
+		}
+	}
+
+	if opts.billing == "PAY_PER_REQUEST" && opts.throughput != nil {
+		return opts, fmt.Errorf("dsql: throughput can't be given with PAY_PER_REQUEST billing")
+	}
+	return opts, nil
+}
@@ -0,0 +1,381 @@
+package dsql
+
+import (
+	"reflect"
+	"testing"
+)
+
+// parse and compile a single statement
+func parseRequest(source string) (Request, error) {
+	stmt, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(stmt)
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, source := range []string{
+		`insert into t (a, b) values (1)`,
+		`create table t (id string hash) with (read = "x")`,
+		`create table t (id string hash) with (speed = 11)`,
+		`create table t (id string hash) with (read = 0, write = 1)`,
+		`create table t (id string hash) with (read = 1)`,
+		`create table t (id string hash) with (billing = monthly)`,
+		`create table t (id string hash) with (class = cold)`,
+		`create table t (id string hash) with (billing = pay_per_request, read = 1, write = 1)`,
+		`create table t (id string hash, a string, index i with (hash = a, read = 1, write = 1)) with (billing = pay_per_request)`,
+		`alter table t set billing = provisioned`,
+		`alter table t set billing = provisioned, read = 5`,
+		`alter table t set billing = pay_per_request, index i (read = 1)`,
+		`alter table t set index i (billing = provisioned)`,
+		`alter table t set write = 1.5`,
+		`alter table t set stream = sideways`,
+	} {
+		stmt, err := Parse(source)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		_, err = Compile(stmt)
+		if err == nil {
+			t.Error("expected error for", source)
+		}
+	}
+}
+
+func TestCompileCreateTableOptionCase(t *testing.T) {
+	actual, err := parseRequest(`create table t (id string hash) WITH (READ=10, Write=5)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CreateTable{
+		TableName:             "t",
+		AttributeDefinitions:  []AttributeDefinition{AttributeDefinition{"id", "S"}},
+		KeySchema:             []Schema{Schema{"id", "HASH"}},
+		ProvisionedThroughput: &Throughput{10, 5},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestCompileCreateTableKeyOrder(t *testing.T) {
+	actual, err := parseRequest(`create table t (n number range, id string hash) with (billing = pay_per_request)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Schema{Schema{"id", "HASH"}, Schema{"n", "RANGE"}}
+	if !reflect.DeepEqual(actual.(CreateTable).KeySchema, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual.(CreateTable).KeySchema)
+	}
+}
+
+func TestCompileCreateTableBilling(t *testing.T) {
+	actual, err := parseRequest(`create table t (id string hash, a string, index i with (hash = a)) with (billing = pay_per_request, CLASS = standard_infrequent_access)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CreateTable{
+		TableName:            "t",
+		AttributeDefinitions: []AttributeDefinition{AttributeDefinition{"id", "S"}, AttributeDefinition{"a", "S"}},
+		KeySchema:            []Schema{Schema{"id", "HASH"}},
+		GlobalSecondaryIndexes: []GlobalSecondaryIndex{
+			GlobalSecondaryIndex{
+				IndexName:  "i",
+				KeySchema:  []Schema{Schema{"a", "HASH"}},
+				Projection: Projection{"ALL", nil},
+			},
+		},
+		BillingMode: "PAY_PER_REQUEST",
+		TableClass:  "STANDARD_INFREQUENT_ACCESS",
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestCompileCreateTableIndexes(t *testing.T) {
+	source := `
+	CREATE TABLE messages (
+		group string HASH,
+		id number RANGE,
+		created string,
+		author string,
+		INDEX created WITH (HASH=group, RANGE=created, PROJECTION=(body, subject)),
+		GLOBAL INDEX by_author WITH (HASH=author, PROJECTION=KEYS_ONLY, READ=5, WRITE=2),
+		global index by_group WITH (hash = group),
+		global index by_created WITH (HASH=created, READ=5)
+	)
+	WITH (READ=10, WRITE=10)`
+
+	expected := CreateTable{
+		TableName: "messages",
+		AttributeDefinitions: []AttributeDefinition{
+			AttributeDefinition{"group", "S"},
+			AttributeDefinition{"id", "N"},
+			AttributeDefinition{"created", "S"},
+			AttributeDefinition{"author", "S"},
+		},
+		KeySchema: []Schema{Schema{"group", "HASH"}, Schema{"id", "RANGE"}},
+		LocalSecondaryIndexes: []LocalSecondaryIndex{
+			LocalSecondaryIndex{
+				IndexName:  "created",
+				KeySchema:  []Schema{Schema{"group", "HASH"}, Schema{"created", "RANGE"}},
+				Projection: Projection{"INCLUDE", []string{"body", "subject"}},
+			},
+		},
+		GlobalSecondaryIndexes: []GlobalSecondaryIndex{
+			GlobalSecondaryIndex{
+				IndexName:             "by_author",
+				KeySchema:             []Schema{Schema{"author", "HASH"}},
+				Projection:            Projection{"KEYS_ONLY", nil},
+				ProvisionedThroughput: &Throughput{5, 2},
+			},
+			GlobalSecondaryIndex{
+				IndexName:             "by_group",
+				KeySchema:             []Schema{Schema{"group", "HASH"}},
+				Projection:            Projection{"ALL", nil},
+				ProvisionedThroughput: &Throughput{10, 10},
+			},
+			GlobalSecondaryIndex{
+				IndexName:             "by_created",
+				KeySchema:             []Schema{Schema{"created", "HASH"}},
+				Projection:            Projection{"ALL", nil},
+				ProvisionedThroughput: &Throughput{5, 10},
+			},
+		},
+		ProvisionedThroughput: &Throughput{10, 10},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestCompileCreateTableIndexErrors(t *testing.T) {
+	for _, source := range []string{
+		// undefined attribute
+		`create table t (id string hash, n number range, index i with (hash = id, range = x))`,
+		// local index on another hash key
+		`create table t (id string hash, n number range, a string, local index i with (hash = a, range = n))`,
+		// local index on a table without range key
+		`create table t (id string hash, n number, local index i with (hash = id, range = n))`,
+		// local index with its own throughput
+		`create table t (id string hash, n number range, m number, local index i with (hash = id, range = m, read = 1))`,
+		// duplicate index
+		`create table t (id string hash, a string, index i with (hash = a), index i with (hash = a))`,
+		// definition that no key uses
+		`create table t (id string hash, a string)`,
+		// index with half a throughput on a table without any
+		`create table t (id string hash, a string, index i with (hash = a, read = 5))`,
+		// no hash key
+		`create table t (id string range)`,
+		// two hash keys
+		`create table t (id string hash, a string hash)`,
+		// two range keys
+		`create table t (id string hash, a string range, b number range)`,
+		// set key
+		`create table t (id stringset hash)`,
+		`create table t (id string hash, n numberset range)`,
+		// set index key
+		`create table t (id string hash, tags binaryset, index i with (hash = tags))`,
+	} {
+		stmt, err := Parse(source)
+		if err != nil {
+			t.Error(source, err)
+			continue
+		}
+		if _, err = Compile(stmt); err == nil {
+			t.Error("expected error for", source)
+		}
+	}
+}
+
+func TestCompileIndexes(t *testing.T) {
+	actual, err := parseRequest("create index by_email on users (email string hash) with (projection = keys_only, read = 5, write = 5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := UpdateTable{
+		TableName:            "users",
+		AttributeDefinitions: []AttributeDefinition{AttributeDefinition{"email", "S"}},
+		GlobalSecondaryIndexUpdates: []GlobalSecondaryIndexUpdate{
+			GlobalSecondaryIndexUpdate{Create: &GlobalSecondaryIndex{
+				IndexName:             "by_email",
+				KeySchema:             []Schema{Schema{"email", "HASH"}},
+				Projection:            Projection{"KEYS_ONLY", nil},
+				ProvisionedThroughput: &Throughput{5, 5},
+			}},
+		},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	actual, err = parseRequest("DROP INDEX by_email ON users WAIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = UpdateTable{
+		TableName: "users",
+		GlobalSecondaryIndexUpdates: []GlobalSecondaryIndexUpdate{
+			GlobalSecondaryIndexUpdate{Delete: &DeleteGlobalSecondaryIndex{"by_email"}},
+		},
+		wait: true,
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	for _, source := range []string{
+		"create index i on t (a hash, b hash)",
+		"create index i on t (a range)",
+		"create index i on t (a stringset hash)",
+	} {
+		if _, err := parseRequest(source); err == nil {
+			t.Error("expected error for", source)
+		}
+	}
+}
+
+func TestCompileAlterTable(t *testing.T) {
+	actual, err := parseRequest("ALTER TABLE users SET BILLING = PROVISIONED, (READ = 50, WRITE = 20), INDEX by_email (READ = 5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := UpdateTable{
+		TableName:             "users",
+		BillingMode:           "PROVISIONED",
+		ProvisionedThroughput: &Throughput{50, 20},
+		GlobalSecondaryIndexUpdates: []GlobalSecondaryIndexUpdate{
+			GlobalSecondaryIndexUpdate{Update: &UpdateGlobalSecondaryIndex{"by_email", Throughput{5, 0}}},
+		},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	actual, err = parseRequest("alter table users set billing = pay_per_request, class = standard")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = UpdateTable{
+		TableName:   "users",
+		BillingMode: "PAY_PER_REQUEST",
+		TableClass:  "STANDARD",
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	actual, err = parseRequest("alter table users set stream = off")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = UpdateTable{
+		TableName:           "users",
+		StreamSpecification: &StreamSpecification{false, ""},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	actual, err = parseRequest("alter table sessions set ttl on expires_at")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = UpdateTable{
+		TableName: "sessions",
+		ttl:       &TimeToLiveSpecification{true, "expires_at"},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestCompileUpdateItems(t *testing.T) {
+	actual, err := parseRequest("UPDATE users SET active = 0 WHERE last_login < 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := UpdateItems{
+		UpdateItem: UpdateItem{
+			TableName:        "users",
+			UpdateExpression: "SET active = :v0",
+			Expressions: Expressions{
+				ExpressionAttributeValues: map[string]Attribute{
+					":v0": Attribute{N: "0"},
+				},
+			},
+		},
+		Where: Expression{Identifier: "last_login", Operator: "<", Value: Attribute{N: "100"}},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	// = joined by OR isn't a key either
+	actual, err = parseRequest("DELETE FROM users WHERE id = 1 OR id = 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := actual.(DeleteItems); !ok {
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestCompileTableGuards(t *testing.T) {
+	actual, err := parseRequest("CREATE TABLE IF NOT EXISTS users (id string HASH) WAIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := actual.(CreateTable); !c.ifNotExists || !c.wait {
+		t.Error("actual  ", c)
+	}
+
+	actual, err = parseRequest("DROP TABLE IF EXISTS users WAIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := DeleteTable{TableName: "users", ifExists: true, wait: true}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
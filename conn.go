@@ -2,8 +2,11 @@
 package dsql
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
+	"time"
 )
 
 func init() {
@@ -17,11 +20,17 @@ func (d *drv) Open(name string) (driver.Conn, error) {
 }
 
 func Open(name string) (driver.Conn, error) {
-	return &conn{cl: GetClient(name)}, nil
+	opts, err := parseOptions(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{cl: GetClient(name), cat: getCatalog(name), opts: opts}, nil
 }
 
 type conn struct {
-	cl Client
+	cl   Client
+	cat  *catalog
+	opts options
 }
 
 func (cn *conn) Prepare(q string) (driver.Stmt, error) {
@@ -44,7 +53,17 @@ func (cn *conn) Rollback() error {
 	return nil
 }
 
-type result struct{}
+// accept anything NewAttribute can encode, e.g. []string for a string set
+func (cn *conn) CheckNamedValue(v *driver.NamedValue) error {
+	if _, err := NewAttribute(v.Value); err != nil {
+		return driver.ErrSkip
+	}
+	return nil
+}
+
+type result struct {
+	affected int64
+}
 
 func (r *result) LastInsertId() (int64, error) {
 	// not very helpful in dynamo
@@ -52,40 +71,597 @@ func (r *result) LastInsertId() (int64, error) {
 	return 0, nil
 }
 
+// items written by INSERT, UPDATE, DELETE and TRUNCATE
 func (r *result) RowsAffected() (int64, error) {
-	return 1, nil
+	return r.affected, nil
 }
 
 func (cn *conn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return cn.exec(context.Background(), query, args)
+}
+
+func (cn *conn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return cn.query(context.Background(), query, args)
+}
+
+// requests stop and waits give up when ctx is done
+func (cn *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values, err := positional(args)
+	if err != nil {
+		return nil, err
+	}
+	return cn.exec(ctx, query, values)
+}
+
+func (cn *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values, err := positional(args)
+	if err != nil {
+		return nil, err
+	}
+	return cn.query(ctx, query, values)
+}
+
+// placeholders are ?, so arguments can't be named
+func positional(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("dsql: named argument %s is not supported", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+func (cn *conn) exec(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
 	// do retry of transient errors here
-	_, err := cn.query(query, args)
+	_, affected, err := cn.script(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return &result{}, nil
+	return &result{affected}, nil
 }
 
-func (cn *conn) Query(query string, args []driver.Value) (driver.Rows, error) {
+func (cn *conn) query(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
 	// do retry of transient errors here
-	return cn.query(query, args)
+	sets, _, err := cn.script(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(sets) {
+	case 0:
+		return &Rows{}, nil
+	case 1:
+		return sets[0], nil
+	}
+	return &resultSets{sets[0], sets[1:]}, nil
 }
 
-func (cn *conn) query(query string, args []driver.Value) (driver.Rows, error) {
+// script runs the statements in order, collects the rows of those that
+// return any and counts the items the others wrote
+func (cn *conn) script(ctx context.Context, query string, args []driver.Value) (sets []*Rows, affected int64, err error) {
 	stmt := Statement{query, args}
 
-	query = stmt.Prepare()
+	query, err = stmt.Prepare()
+	if err != nil {
+		return nil, 0, err
+	}
 
-	req, err := Parse(query)
+	stmts, err := ParseScript(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// compile everything first so a bad statement doesn't leave the
+	// script half done
+	var reqs []Request
+	for _, stmt := range stmts {
+		req, err := Compile(stmt)
+		if err != nil {
+			return nil, 0, err
+		}
+		reqs = append(reqs, req)
+	}
+
+	for _, req := range reqs {
+		switch r := req.(type) {
+		case TruncateTable:
+			n, err := cn.truncate(ctx, r)
+			affected += n
+			if err != nil {
+				return nil, affected, err
+			}
+			continue
+		case UpdateItem, DeleteItem, UpdateItems, DeleteItems:
+			n, err := cn.write(ctx, r)
+			affected += n
+			if err != nil {
+				return nil, affected, err
+			}
+			continue
+		}
+
+		rows, err := cn.execute(ctx, req)
+		if err != nil {
+			return nil, affected, err
+		}
+		if rows != nil {
+			sets = append(sets, rows)
+		}
+
+		if _, ok := req.(PutItem); ok {
+			affected++
+		}
+	}
+
+	return sets, affected, nil
+}
+
+// write carries out an UPDATE or DELETE. A key lookup is a single request;
+// with ?multi=true it has to name the table's whole key, and any other WHERE
+// turns into a request per matching item
+func (cn *conn) write(ctx context.Context, req Request) (int64, error) {
+	var name string
+	switch r := req.(type) {
+	case UpdateItem:
+		name = r.TableName
+	case DeleteItem:
+		name = r.TableName
+	case UpdateItems:
+		name = r.TableName
+	case DeleteItems:
+		name = r.TableName
+	}
+
+	var table *TableDescription
+	if cn.opts.multi {
+		var err error
+		if table, err = cn.table(ctx, name); err != nil {
+			return 0, err
+		}
+	}
+
+	switch r := req.(type) {
+	case UpdateItem:
+		if table != nil && !table.lookup(r.Key) {
+			where := keyWhere(r.Key)
+			r.Key = nil
+			req = UpdateItems{r, where}
+		}
+	case DeleteItem:
+		if table != nil && !table.lookup(r.Key) {
+			where := keyWhere(r.Key)
+			r.Key = nil
+			req = DeleteItems{r, where}
+		}
+	}
+
+	cl := withContext(ctx, cn.cl)
+	notLookup := fmt.Errorf("dsql: WHERE on %s isn't a key lookup, add ?multi=true to the data source name to query or scan for the items", name)
+	switch r := req.(type) {
+	case UpdateItems:
+		if table == nil {
+			return 0, notLookup
+		}
+		return r.run(cl, table)
+	case DeleteItems:
+		if table == nil {
+			return 0, notLookup
+		}
+		return r.run(cl, table)
+	}
+
+	body, err := cl.Post(req)
+	if err != nil {
+		return 0, err
+	}
+	body.Close()
+	return 1, nil
+}
+
+// truncate deletes the items of a table by its keys, which are looked up
+func (cn *conn) truncate(ctx context.Context, t TruncateTable) (int64, error) {
+	table, err := cn.describe(ctx, t.TableName)
+	if err != nil {
+		return 0, err
+	}
+	return t.run(ctx, withContext(ctx, cn.cl), table.keys())
+}
+
+func (cn *conn) execute(ctx context.Context, req Request) (*Rows, error) {
+	cl := withContext(ctx, cn.cl)
+
+	switch r := req.(type) {
+	case UpdateTable:
+		return nil, cn.updateTable(ctx, r)
+	case CreateTable:
+		return nil, cn.createTable(ctx, r)
+	case DeleteTable:
+		return nil, cn.deleteTable(ctx, r)
+	case *ListTables:
+		return r.fetch(cl)
+	case DescribeTable:
+		table, err := cn.describe(ctx, r.TableName)
+		if err != nil {
+			return nil, err
+		}
+		ttl, err := cn.ttl(ctx, r.TableName)
+		if err != nil {
+			return nil, err
+		}
+		return describeRows(table, ttl), nil
+	case ShowCreateTable:
+		table, err := cn.describe(ctx, r.TableName)
+		if err != nil {
+			return nil, err
+		}
+		ttl, err := cn.ttl(ctx, r.TableName)
+		if err != nil {
+			return nil, err
+		}
+		ddl := Format(createStatement(table, ttl))
+		return stringRows([]string{"table", "create_table"}, [][]string{[]string{table.TableName, ddl}}), nil
+	case *SchemaQuery:
+		list := func() ([]string, error) {
+			return (&ListTables{}).names(cl)
+		}
+		describe := func(name string) (*TableDescription, error) {
+			return cn.describe(ctx, name)
+		}
+		return r.fetch(list, describe)
+	}
+
+	if q, ok := req.(*Query); ok {
+		if cn.opts.skipExpired {
+			ttl, known := cn.cat.TTL(q.TableName)
+			if !known {
+				var err error
+				if ttl, err = cn.ttl(ctx, q.TableName); err != nil {
+					return nil, err
+				}
+			}
+			if ttl != "" {
+				q.SkipExpired(ttl, time.Now())
+			}
+		}
+
+		// the key schema only orders and types the columns, without
+		// permission to describe the table they are inferred from the items
+		table, err := cn.table(ctx, q.TableName)
+		if err != nil && (ctx.Err() != nil || q.orderBy != "") {
+			return nil, err
+		}
+		if table != nil {
+			if err := q.order(table); err != nil {
+				return nil, err
+			}
+		}
+		rows, err := q.fetch(cl, table)
+		if err != nil {
+			return nil, err
+		}
+		rows.emptyNulls = cn.opts.emptyNulls
+		return rows, nil
+	}
+
+	body, err := cl.Post(req)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
 
-	body, err := cn.cl.Post(req)
+	rows, err := req.Rows(body)
+	r, _ := rows.(*Rows)
+	return r, err
+}
+
+// updateTable fills in what CREATE INDEX and ALTER TABLE left out from the
+// live table, changes the time to live and waits for the indexes when asked
+// to
+func (cn *conn) updateTable(ctx context.Context, u UpdateTable) error {
+	if !u.empty() {
+		if err := cn.complete(ctx, &u); err != nil {
+			return err
+		}
+
+		body, err := withContext(ctx, cn.cl).Post(u)
+		if err != nil {
+			return err
+		}
+		body.Close()
+	}
+
+	if u.ttl != nil {
+		if err := cn.timeToLive(ctx, u.TableName, *u.ttl); err != nil {
+			return err
+		}
+	}
+
+	if !u.wait && !cn.opts.wait {
+		return nil
+	}
+	for _, update := range u.GlobalSecondaryIndexUpdates {
+		var err error
+		switch {
+		case update.Create != nil:
+			err = cn.waitIndex(ctx, u.TableName, update.Create.IndexName, false)
+		case update.Delete != nil:
+			err = cn.waitIndex(ctx, u.TableName, update.Delete.IndexName, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// complete adds the definitions of key attributes without a type, the
+// table's throughput to created indexes and the current capacity where
+// ALTER TABLE changes only one of read and write
+func (cn *conn) complete(ctx context.Context, u *UpdateTable) error {
+	missing := u.missing()
+	needed := len(missing) > 0 || partial(u.ProvisionedThroughput)
+	for _, update := range u.GlobalSecondaryIndexUpdates {
+		if update.Create != nil && update.Create.ProvisionedThroughput == nil {
+			needed = true
+		}
+		if update.Update != nil && partial(&update.Update.ProvisionedThroughput) {
+			needed = true
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	table, err := cn.describe(ctx, u.TableName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range missing {
+		def := table.definition(name)
+		if def == nil {
+			return fmt.Errorf("dsql: type of %s is unknown, give it in the key list, e.g. (%s string HASH)", name, name)
+		}
+		u.AttributeDefinitions = append(u.AttributeDefinitions, *def)
+	}
+
+	// on-demand tables report zero throughput, their indexes take none
+	throughput := table.ProvisionedThroughput
+	if throughput != nil && throughput.ReadCapacityUnits == 0 {
+		throughput = nil
+	}
+	for _, update := range u.GlobalSecondaryIndexUpdates {
+		if update.Create != nil && update.Create.ProvisionedThroughput == nil {
+			update.Create.ProvisionedThroughput = throughput
+		}
+	}
+
+	if partial(u.ProvisionedThroughput) {
+		if err := fill(u.ProvisionedThroughput, table.ProvisionedThroughput, u.TableName); err != nil {
+			return err
+		}
+	}
+	for _, update := range u.GlobalSecondaryIndexUpdates {
+		if update.Update == nil || !partial(&update.Update.ProvisionedThroughput) {
+			continue
+		}
+		idx := table.index(update.Update.IndexName)
+		if idx == nil {
+			return fmt.Errorf("dsql: %s has no global index %s", u.TableName, update.Update.IndexName)
+		}
+		if err := fill(&update.Update.ProvisionedThroughput, idx.ProvisionedThroughput, idx.IndexName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partial tells if only one of read and write capacity is given
+func partial(t *Throughput) bool {
+	return t != nil && (t.ReadCapacityUnits == 0) != (t.WriteCapacityUnits == 0)
+}
+
+// fill sets the capacity left out of t to the current one
+func fill(t, current *Throughput, name string) error {
+	if current == nil || current.ReadCapacityUnits == 0 {
+		return fmt.Errorf("dsql: %s has no provisioned throughput, give both read and write", name)
+	}
+	if t.ReadCapacityUnits == 0 {
+		t.ReadCapacityUnits = current.ReadCapacityUnits
+	}
+	if t.WriteCapacityUnits == 0 {
+		t.WriteCapacityUnits = current.WriteCapacityUnits
+	}
+	return nil
+}
+
+// describe fetches the live layout of a table and remembers it
+func (cn *conn) describe(ctx context.Context, name string) (*TableDescription, error) {
+	req := DescribeTable{name}
+	body, err := withContext(ctx, cn.cl).Post(req)
 	if err != nil {
 		return nil, err
 	}
 	defer body.Close()
 
-	return req.Rows(body)
+	table, err := req.decode(body)
+	if err != nil {
+		return nil, err
+	}
+	cn.cat.Add(table)
+	return table, nil
+}
+
+// table returns the layout of a table from the catalog, describing it the
+// first time
+func (cn *conn) table(ctx context.Context, name string) (*TableDescription, error) {
+	if table := cn.cat.Table(name); table != nil {
+		return table, nil
+	}
+	return cn.describe(ctx, name)
+}
+
+// ttl returns the attribute holding the expiry time, if time to live is on,
+// and remembers it
+func (cn *conn) ttl(ctx context.Context, table string) (string, error) {
+	req := DescribeTimeToLive{table}
+	body, err := withContext(ctx, cn.cl).Post(req)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	desc, err := req.decode(body)
+	if err != nil {
+		return "", err
+	}
+	attr := ""
+	switch desc.TimeToLiveStatus {
+	case "ENABLING", "ENABLED":
+		attr = desc.AttributeName
+	}
+	cn.cat.SetTTL(table, attr)
+	return attr, nil
+}
+
+// timeToLive turns time to live on or off, unless it already is; turning it
+// off needs the attribute it is on for
+func (cn *conn) timeToLive(ctx context.Context, table string, spec TimeToLiveSpecification) error {
+	current, err := cn.ttl(ctx, table)
+	if err != nil {
+		return err
+	}
+	if spec.Enabled && current == spec.AttributeName || !spec.Enabled && current == "" {
+		return nil
+	}
+	if !spec.Enabled {
+		spec.AttributeName = current
+	}
+
+	body, err := withContext(ctx, cn.cl).Post(UpdateTimeToLive{table, spec})
+	if err != nil {
+		return err
+	}
+	body.Close()
+
+	if !spec.Enabled {
+		spec.AttributeName = ""
+	}
+	cn.cat.SetTTL(table, spec.AttributeName)
+	return nil
+}
+
+// how often DescribeTable is polled while waiting, and for how long when
+// neither the context nor the DSN says
+var (
+	pollInterval = 5 * time.Second
+	waitTimeout  = 20 * time.Minute
+)
+
+// createTable ignores an existing table for IF NOT EXISTS and waits for the
+// table to become active when asked to, or to turn on time to live
+func (cn *conn) createTable(ctx context.Context, c CreateTable) error {
+	created := false
+	body, err := withContext(ctx, cn.cl).Post(c)
+	switch {
+	case err == nil:
+		body.Close()
+		cn.cat.Add(c.description())
+		created = true
+	case c.ifNotExists && isError(err, "ResourceInUseException"):
+	default:
+		return err
+	}
+
+	// an existing table keeps its time to live
+	ttl := created && c.ttl != ""
+	if !c.wait && !cn.opts.wait && !ttl {
+		return nil
+	}
+	err = cn.poll(ctx, "table "+c.TableName, func() (bool, error) {
+		desc, err := cn.describe(ctx, c.TableName)
+		return err == nil && desc.TableStatus == "ACTIVE", err
+	})
+	if err != nil || !ttl {
+		return err
+	}
+
+	body, err = withContext(ctx, cn.cl).Post(UpdateTimeToLive{c.TableName, TimeToLiveSpecification{true, c.ttl}})
+	if err != nil {
+		return err
+	}
+	body.Close()
+	cn.cat.SetTTL(c.TableName, c.ttl)
+	return nil
+}
+
+// deleteTable ignores a missing table for IF EXISTS and waits for the table
+// to be gone when asked to
+func (cn *conn) deleteTable(ctx context.Context, d DeleteTable) error {
+	body, err := withContext(ctx, cn.cl).Post(d)
+	switch {
+	case err == nil:
+		body.Close()
+	case d.ifExists && isError(err, "ResourceNotFoundException"):
+	default:
+		return err
+	}
+	cn.cat.Remove(d.TableName)
+
+	if !d.wait && !cn.opts.wait {
+		return nil
+	}
+	err = cn.poll(ctx, "table "+d.TableName, func() (bool, error) {
+		_, err := cn.describe(ctx, d.TableName)
+		if isError(err, "ResourceNotFoundException") {
+			return true, nil
+		}
+		return false, err
+	})
+	cn.cat.Remove(d.TableName)
+	return err
+}
+
+// waitIndex polls until the index is active, or gone
+func (cn *conn) waitIndex(ctx context.Context, table, index string, gone bool) error {
+	return cn.poll(ctx, "index "+index+" on "+table, func() (bool, error) {
+		desc, err := cn.describe(ctx, table)
+		if err != nil {
+			return false, err
+		}
+		idx := desc.index(index)
+		return gone && idx == nil || !gone && idx != nil && idx.IndexStatus == "ACTIVE", nil
+	})
+}
+
+// poll calls done every pollInterval until it returns true or an error, or
+// until ctx is done; without a deadline on ctx it gives up after the DSN's
+// wait_timeout or waitTimeout
+func (cn *conn) poll(ctx context.Context, what string, done func() (bool, error)) error {
+	var deadline time.Time
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := waitTimeout
+		if cn.opts.waitTimeout > 0 {
+			timeout = cn.opts.waitTimeout
+		}
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		ok, err := done()
+		if ok || err != nil {
+			return err
+		}
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("dsql: timed out waiting for %s", what)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
 }
@@ -2,8 +2,8 @@ package dsql
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"io"
-	"strconv"
 	"strings"
 )
 
@@ -26,44 +26,246 @@ type Schema struct {
 	KeyType       string
 }
 
-type CreateTable struct {
-	TableName             string
-	AttributeDefinitions  []AttributeDefinition
+type Throughput struct {
+	ReadCapacityUnits  int
+	WriteCapacityUnits int
+}
+
+type Projection struct {
+	ProjectionType   string
+	NonKeyAttributes []string `json:",omitempty"`
+}
+
+type LocalSecondaryIndex struct {
+	IndexName  string
+	KeySchema  []Schema
+	Projection Projection
+}
+
+// IndexStatus is only set in DescribeTable responses
+type GlobalSecondaryIndex struct {
+	IndexName             string
 	KeySchema             []Schema
-	ProvisionedThroughput struct {
-		ReadCapacityUnits  int
-		WriteCapacityUnits int
-	}
+	Projection            Projection
+	ProvisionedThroughput *Throughput `json:",omitempty"`
+	IndexStatus           string      `json:",omitempty"`
+}
+
+var ProjectionTypes = map[string]string{
+	"":          "ALL",
+	"all":       "ALL",
+	"keys_only": "KEYS_ONLY",
+	"include":   "INCLUDE",
+}
+
+type CreateTable struct {
+	TableName              string
+	AttributeDefinitions   []AttributeDefinition
+	KeySchema              []Schema
+	LocalSecondaryIndexes  []LocalSecondaryIndex  `json:",omitempty"`
+	GlobalSecondaryIndexes []GlobalSecondaryIndex `json:",omitempty"`
+	BillingMode            string                 `json:",omitempty"`
+	ProvisionedThroughput  *Throughput            `json:",omitempty"`
+	StreamSpecification    *StreamSpecification   `json:",omitempty"`
+	TableClass             string                 `json:",omitempty"`
+
+	ifNotExists bool   // an existing table isn't an error
+	wait        bool   // until the table is active
+	ttl         string // attribute to expire items by, once the table is active
 }
 
+// the view type is left out when disabling a stream
+type StreamSpecification struct {
+	StreamEnabled  bool
+	StreamViewType string `json:",omitempty"`
+}
+
+var StreamViewTypes = []string{"KEYS_ONLY", "NEW_IMAGE", "OLD_IMAGE", "NEW_AND_OLD_IMAGES"}
+
+var BillingModes = []string{"PROVISIONED", "PAY_PER_REQUEST"}
+
+var TableClasses = []string{"STANDARD", "STANDARD_INFREQUENT_ACCESS"}
+
 func (c *CreateTable) AddDefinition(d Definition) {
 	c.AttributeDefinitions = append(
 		c.AttributeDefinitions,
 		AttributeDefinition{d.Identifier, DefinitionTypes[d.Type]},
 	)
 
-	if d.Constraint != "" {
-		c.KeySchema = append(
-			c.KeySchema,
-			Schema{d.Identifier, strings.ToUpper(d.Constraint)},
-		)
+	// the hash key goes first whatever order the keys are defined in
+	key := Schema{d.Identifier, strings.ToUpper(d.Constraint)}
+	switch key.KeyType {
+	case "HASH":
+		c.KeySchema = append([]Schema{key}, c.KeySchema...)
+	case "RANGE":
+		c.KeySchema = append(c.KeySchema, key)
 	}
 }
 
-func (c *CreateTable) AddThroughput(exp Expression) {
-	units, err := strconv.Atoi(exp.Text)
-	if err != nil {
-		panic("throughput must be an integer")
+// AddIndex adds a secondary index; a local index shares the table's hash
+// key, global indexes get the table's throughput for the capacities they
+// leave out
+func (c *CreateTable) AddIndex(idx IndexDefinition) error {
+	for _, name := range []string{idx.Hash, idx.Range} {
+		if name != "" && !c.defined(name) {
+			return fmt.Errorf("dsql: index %s uses undefined attribute %s", idx.Name, name)
+		}
+	}
+	if idx.Hash == "" {
+		return fmt.Errorf("dsql: index %s needs a hash key", idx.Name)
+	}
+	if c.indexed(idx.Name) {
+		return fmt.Errorf("dsql: duplicate index %s", idx.Name)
+	}
+	if idx.Projection == "include" && len(idx.Include) == 0 {
+		return fmt.Errorf("dsql: index %s includes no attributes", idx.Name)
+	}
+
+	keys := []Schema{Schema{idx.Hash, "HASH"}}
+	if idx.Range != "" {
+		keys = append(keys, Schema{idx.Range, "RANGE"})
+	}
+	projection := Projection{ProjectionTypes[idx.Projection], idx.Include}
+
+	hash, tableRange := c.key("HASH"), c.key("RANGE")
+	scope := idx.Scope
+	if scope == "" {
+		scope = "global"
+		if idx.Hash == hash && idx.Range != "" {
+			scope = "local"
+		}
 	}
 
-	switch exp.Identifier {
-	case "read":
-		c.ProvisionedThroughput.ReadCapacityUnits = units
-	case "write":
-		c.ProvisionedThroughput.WriteCapacityUnits = units
+	if scope == "local" {
+		switch {
+		case idx.Hash != hash:
+			return fmt.Errorf("dsql: local index %s must use the table's hash key %s", idx.Name, hash)
+		case idx.Range == "" || tableRange == "":
+			return fmt.Errorf("dsql: local index %s needs a range key on both table and index", idx.Name)
+		case idx.Read != 0 || idx.Write != 0:
+			return fmt.Errorf("dsql: local index %s shares the table's throughput", idx.Name)
+		}
+		c.LocalSecondaryIndexes = append(
+			c.LocalSecondaryIndexes,
+			LocalSecondaryIndex{idx.Name, keys, projection},
+		)
+		return nil
+	}
+
+	var throughput *Throughput
+	switch {
+	case idx.Read == 0 && idx.Write == 0:
+		if c.ProvisionedThroughput != nil {
+			t := *c.ProvisionedThroughput
+			throughput = &t
+		}
+	case c.BillingMode == "PAY_PER_REQUEST":
+		return fmt.Errorf("dsql: index %s can't have throughput, the table is PAY_PER_REQUEST", idx.Name)
 	default:
-		panic("unknown create table parameter (expected read or write)")
+		throughput = &Throughput{idx.Read, idx.Write}
+		if partial(throughput) {
+			if c.ProvisionedThroughput == nil {
+				return fmt.Errorf("dsql: index %s needs both read and write throughput, the table has none", idx.Name)
+			}
+			fill(throughput, c.ProvisionedThroughput, c.TableName)
+		}
+	}
+	c.GlobalSecondaryIndexes = append(c.GlobalSecondaryIndexes, GlobalSecondaryIndex{
+		IndexName:             idx.Name,
+		KeySchema:             keys,
+		Projection:            projection,
+		ProvisionedThroughput: throughput,
+	})
+	return nil
+}
+
+// every attribute definition has to belong to a key of the table or an index
+func (c *CreateTable) validate() error {
+	if len(c.KeySchema) == 0 || c.KeySchema[0].KeyType != "HASH" ||
+		len(c.KeySchema) > 2 || len(c.KeySchema) == 2 && c.KeySchema[1].KeyType != "RANGE" {
+		return fmt.Errorf("dsql: table %s needs one hash key and at most one range key", c.TableName)
+	}
+	for _, def := range c.AttributeDefinitions {
+		if !c.keyed(def.AttributeName) {
+			return fmt.Errorf("dsql: attribute %s is not part of a key, only key attributes are defined", def.AttributeName)
+		}
+		switch def.AttributeType {
+		case "S", "N", "B":
+		default:
+			return fmt.Errorf("dsql: key %s must be a string, number or binary", def.AttributeName)
+		}
+	}
+	return nil
+}
+
+func (c *CreateTable) defined(name string) bool {
+	for _, def := range c.AttributeDefinitions {
+		if def.AttributeName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CreateTable) indexed(name string) bool {
+	for _, idx := range c.LocalSecondaryIndexes {
+		if idx.IndexName == name {
+			return true
+		}
+	}
+	for _, idx := range c.GlobalSecondaryIndexes {
+		if idx.IndexName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CreateTable) keyed(name string) bool {
+	schemas := [][]Schema{c.KeySchema}
+	for _, idx := range c.LocalSecondaryIndexes {
+		schemas = append(schemas, idx.KeySchema)
+	}
+	for _, idx := range c.GlobalSecondaryIndexes {
+		schemas = append(schemas, idx.KeySchema)
+	}
+	for _, keys := range schemas {
+		for _, k := range keys {
+			if k.AttributeName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// the table's attribute of the given key type
+func (c *CreateTable) key(kind string) string {
+	for _, k := range c.KeySchema {
+		if k.KeyType == kind {
+			return k.AttributeName
+		}
+	}
+	return ""
+}
+
+func (c CreateTable) description() *TableDescription {
+	desc := &TableDescription{
+		TableName:              c.TableName,
+		AttributeDefinitions:   c.AttributeDefinitions,
+		KeySchema:              c.KeySchema,
+		LocalSecondaryIndexes:  c.LocalSecondaryIndexes,
+		GlobalSecondaryIndexes: c.GlobalSecondaryIndexes,
+		ProvisionedThroughput:  c.ProvisionedThroughput,
+		StreamSpecification:    c.StreamSpecification,
+	}
+	if c.BillingMode != "" {
+		desc.BillingModeSummary = &BillingModeSummary{c.BillingMode}
+	}
+	if c.TableClass != "" {
+		desc.TableClassSummary = &TableClassSummary{c.TableClass}
 	}
+	return desc
 }
 
 func (c CreateTable) Rows(body io.ReadCloser) (driver.Rows, error) {
@@ -0,0 +1,101 @@
+package dsql
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Decimal holds a DynamoDB number without losing any of its 38 digits.
+// Numbers that don't fit an int64 are returned as strings, scan them into
+// a Decimal (or a string) to keep their precision.
+type Decimal string
+
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*d = Decimal(strconv.FormatInt(v, 10))
+	case float64:
+		s, err := formatFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*d = Decimal(s)
+	case string:
+		return d.set(v)
+	case []byte:
+		return d.set(string(v))
+	default:
+		return fmt.Errorf("dsql: cannot scan %T into Decimal", src)
+	}
+	return nil
+}
+
+func (d *Decimal) set(s string) error {
+	if _, ok := new(big.Rat).SetString(s); !ok {
+		return fmt.Errorf("dsql: %q is not a number", s)
+	}
+	*d = Decimal(s)
+	return nil
+}
+
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Rat returns the exact value, ok is false if d isn't a number
+func (d Decimal) Rat() (r *big.Rat, ok bool) {
+	return new(big.Rat).SetString(string(d))
+}
+
+// int64 when integral and in range, otherwise the decimal text
+func parseNumber(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if ok && r.IsInt() && r.Num().IsInt64() {
+		return r.Num().Int64()
+	}
+	return s
+}
+
+func formatFloat(f float64, bits int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("dsql: %v is not a valid number", f)
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits), nil
+}
+
+// formatRat writes r exactly, failing for repeating fractions like 1/3
+func formatRat(r *big.Rat) (string, error) {
+	if r.IsInt() {
+		return r.Num().String(), nil
+	}
+
+	// a fraction terminates when its denominator only has factors 2 and 5
+	d := new(big.Int).Set(r.Denom())
+	places := 0
+	for _, f := range []int64{2, 5} {
+		n := 0
+		m := new(big.Int)
+		for {
+			q, rem := new(big.Int).QuoRem(d, big.NewInt(f), m)
+			if rem.Sign() != 0 {
+				break
+			}
+			d = q
+			n++
+		}
+		if n > places {
+			places = n
+		}
+	}
+
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("dsql: %s has no exact decimal representation", r)
+	}
+	return r.FloatString(places), nil
+}
@@ -0,0 +1,88 @@
+package dsql
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestAttributeValueNumbers(t *testing.T) {
+	cases := map[string]interface{}{
+		"1":                                      int64(1),
+		"-20":                                    int64(-20),
+		"1E2":                                    int64(100),
+		"3.14":                                   "3.14",
+		"9223372036854775808":                    "9223372036854775808",
+		"12345678901234567890123456789012345678": "12345678901234567890123456789012345678",
+	}
+
+	for n, expected := range cases {
+		actual := Attribute{N: n}.Value()
+		if actual != expected {
+			t.Errorf("expected %#v", expected)
+			t.Errorf("actual   %#v", actual)
+		}
+	}
+}
+
+func TestNewAttributeNumbers(t *testing.T) {
+	huge, _ := new(big.Int).SetString("12345678901234567890123456789012345678", 10)
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{3.14, "3.14"},
+		{float32(0.1), "0.1"},
+		{uint64(math.MaxUint64), "18446744073709551615"},
+		{huge, "12345678901234567890123456789012345678"},
+		{big.NewRat(1, 8), "0.125"},
+		{big.NewRat(-7, 20), "-0.35"},
+		{big.NewRat(10, 2), "5"},
+		{Decimal("1.50"), "1.50"},
+	}
+
+	for _, c := range cases {
+		a, err := NewAttribute(c.value)
+		if err != nil {
+			t.Error(err)
+		}
+		if a.N != c.expected {
+			t.Error("expected ", c.expected)
+			t.Error("actual   ", a.N)
+		}
+	}
+
+	for _, v := range []interface{}{big.NewRat(1, 3), math.NaN(), math.Inf(1), Decimal("abc")} {
+		if _, err := NewAttribute(v); err == nil {
+			t.Error("expected error for", v)
+		}
+	}
+}
+
+func TestDecimalScan(t *testing.T) {
+	var d Decimal
+
+	for src, expected := range map[interface{}]string{
+		int64(5):  "5",
+		1.25:      "1.25",
+		"1.1E+40": "1.1E+40",
+	} {
+		if err := d.Scan(src); err != nil {
+			t.Error(err)
+		}
+		if d.String() != expected {
+			t.Error("expected ", expected)
+			t.Error("actual   ", d)
+		}
+	}
+
+	if err := d.Scan("abc"); err == nil {
+		t.Error("expected error")
+	}
+
+	d = Decimal("0.1")
+	r, ok := d.Rat()
+	if !ok || r.Cmp(big.NewRat(1, 10)) != 0 {
+		t.Error("bad rat", r)
+	}
+}
@@ -6,8 +6,10 @@ import (
 )
 
 type DeleteItem struct {
-	TableName string
-	Key       Item
+	TableName           string
+	Key                 Item
+	ConditionExpression string `json:",omitempty"`
+	Expressions
 }
 
 func (d *DeleteItem) AddKey(exp Expression) {
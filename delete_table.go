@@ -7,6 +7,9 @@ import (
 
 type DeleteTable struct {
 	TableName string
+
+	ifExists bool // a missing table isn't an error
+	wait     bool // until the table is gone
 }
 
 func (d DeleteTable) Rows(body io.ReadCloser) (driver.Rows, error) {
@@ -0,0 +1,81 @@
+package dsql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+type DescribeTable struct {
+	TableName string
+}
+
+func (d DescribeTable) decode(body io.Reader) (*TableDescription, error) {
+	var res struct {
+		Table TableDescription
+	}
+	if err := json.NewDecoder(body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res.Table, nil
+}
+
+func (d DescribeTable) Rows(body io.ReadCloser) (driver.Rows, error) {
+	table, err := d.decode(body)
+	if err != nil {
+		return nil, err
+	}
+	return describeRows(table, ""), nil
+}
+
+// one row per defined attribute, keys of the table first: its type, its role
+// in the table's key and the indexes it is a key of, e.g. "by_email HASH".
+// The time to live attribute ttl, if any, is marked TTL in extra and gets a
+// row of its own unless it is a key
+func describeRows(t *TableDescription, ttl string) *Rows {
+	var values [][]string
+	for _, name := range t.attributes() {
+		typ := typeNames[t.definition(name).AttributeType]
+
+		var indexes []string
+		for _, idx := range t.LocalSecondaryIndexes {
+			if kt := keyType(idx.KeySchema, name); kt != "" {
+				indexes = append(indexes, idx.IndexName+" "+kt)
+			}
+		}
+		for _, idx := range t.GlobalSecondaryIndexes {
+			if kt := keyType(idx.KeySchema, name); kt != "" {
+				indexes = append(indexes, idx.IndexName+" "+kt)
+			}
+		}
+
+		extra := ""
+		if name == ttl {
+			extra = "TTL"
+		}
+		values = append(values, []string{name, typ, keyType(t.KeySchema, name), strings.Join(indexes, ", "), extra})
+	}
+	if ttl != "" && t.definition(ttl) == nil {
+		values = append(values, []string{ttl, "number", "", "", "TTL"})
+	}
+	return stringRows([]string{"attribute", "type", "key", "indexes", "extra"}, values)
+}
+
+// the dialect's name for each attribute type
+var typeNames = map[string]string{}
+
+func init() {
+	for name, typ := range DefinitionTypes {
+		typeNames[typ] = name
+	}
+}
+
+func keyType(keys []Schema, name string) string {
+	for _, k := range keys {
+		if k.AttributeName == name {
+			return k.KeyType
+		}
+	}
+	return ""
+}
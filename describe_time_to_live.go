@@ -0,0 +1,31 @@
+package dsql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+)
+
+type DescribeTimeToLive struct {
+	TableName string
+}
+
+// TimeToLiveStatus is ENABLING, ENABLED, DISABLING or DISABLED
+type TimeToLiveDescription struct {
+	TimeToLiveStatus string
+	AttributeName    string `json:",omitempty"`
+}
+
+func (d DescribeTimeToLive) decode(body io.Reader) (*TimeToLiveDescription, error) {
+	var res struct {
+		TimeToLiveDescription TimeToLiveDescription
+	}
+	if err := json.NewDecoder(body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res.TimeToLiveDescription, nil
+}
+
+func (d DescribeTimeToLive) Rows(body io.ReadCloser) (driver.Rows, error) {
+	return nil, nil
+}
@@ -0,0 +1,104 @@
+// Build DynamoDB expressions with placeholders for names and values
+package dsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Attribute names that can't appear in an expression as is (reserved words,
+// dashes, dots, ...) become #n0, #n1, ... and every value becomes :v0, :v1
+type Expressions struct {
+	ExpressionAttributeNames  map[string]string    `json:",omitempty"`
+	ExpressionAttributeValues map[string]Attribute `json:",omitempty"`
+}
+
+func (e *Expressions) Name(name string) string {
+	if plain(name) && !isReserved(name) {
+		return name
+	}
+
+	for k, v := range e.ExpressionAttributeNames {
+		if v == name {
+			return k
+		}
+	}
+
+	if e.ExpressionAttributeNames == nil {
+		e.ExpressionAttributeNames = map[string]string{}
+	}
+
+	k := "#n" + strconv.Itoa(len(e.ExpressionAttributeNames))
+	e.ExpressionAttributeNames[k] = name
+	return k
+}
+
+func (e *Expressions) Value(a Attribute) string {
+	if e.ExpressionAttributeValues == nil {
+		e.ExpressionAttributeValues = map[string]Attribute{}
+	}
+
+	k := ":v" + strconv.Itoa(len(e.ExpressionAttributeValues))
+	e.ExpressionAttributeValues[k] = a
+	return k
+}
+
+// Condition renders a comparison, e.g. "#n0 BETWEEN :v0 AND :v1"
+func (e *Expressions) Condition(exp Expression) string {
+	name := e.Name(exp.Identifier)
+
+	switch exp.Operator {
+	case "between":
+		return name + " BETWEEN " + e.Value(exp.Value) + " AND " + e.Value(exp.Between)
+	case "like":
+		return "begins_with(" + name + ", " + e.Value(exp.Value) + ")"
+	}
+	return name + " " + exp.Operator + " " + e.Value(exp.Value)
+}
+
+// Predicate renders a WHERE condition, e.g. "a = :v0 AND (b < :v1 OR c > :v2)";
+// conditions are joined left to right as the parser reads them
+func (e *Expressions) Predicate(x Expr) string {
+	switch x := x.(type) {
+	case Expression:
+		return e.Condition(x)
+	case Logical:
+		return e.operand(x.Left) + " " + strings.ToUpper(x.Operator) + " " + e.operand(x.Right)
+	}
+	return ""
+}
+
+func (e *Expressions) operand(x Expr) string {
+	if _, ok := x.(Logical); ok {
+		return "(" + e.Predicate(x) + ")"
+	}
+	return e.Predicate(x)
+}
+
+// original returns the attribute name behind a placeholder
+func (e *Expressions) original(s string) string {
+	if name, ok := e.ExpressionAttributeNames[s]; ok {
+		return name
+	}
+	return s
+}
+
+// letters, digits and underscores, starting with a letter
+func plain(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '_' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+func join(expr, sep, s string) string {
+	if expr == "" {
+		return s
+	}
+	return strings.Join([]string{expr, s}, sep)
+}
@@ -0,0 +1,114 @@
+// Group statements that differ only in their literals
+package dsql
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// Fingerprint normalizes sql for metrics and logs: literals, lists, maps and
+// sets become ?, keywords and options are upper case and whitespace and
+// comments are collapsed, so "... WHERE id = 1" and "... where id = 2" share a hash
+func Fingerprint(sql string) (hash uint64, normalized string) {
+	var lexemes []Lexeme
+	l := NewLexer(strings.NewReader(sql))
+	for t := l.Peek(); t != EOF; t = l.Next() {
+		lexemes = append(lexemes, l.Lexeme())
+	}
+
+	// trailing semicolons don't change the statement
+	for len(lexemes) > 0 && lexemes[len(lexemes)-1].Token == Semicolon {
+		lexemes = lexemes[:len(lexemes)-1]
+	}
+
+	var b strings.Builder
+	var prev Token = EOF
+	var options bool // in a create or alter, where names before = are options
+	for i := 0; i < len(lexemes); i++ {
+		lx := lexemes[i]
+		if prev == EOF || prev == Semicolon {
+			options = lx.Token == Keyword && (strings.EqualFold(lx.Text, "create") || strings.EqualFold(lx.Text, "alter"))
+		}
+
+		text := lx.Text
+		switch lx.Token {
+		case String, Number, Boolean, Null, Binary:
+			text = "?"
+		case LeftBracket, LeftBrace:
+			i = closing(lexemes, i)
+			text = "?"
+		case Operator:
+			if lx.Text == "<" && i+1 < len(lexemes) && lexemes[i+1].Text == "<" {
+				i = closingSet(lexemes, i)
+				text = "?"
+			} else {
+				text = strings.ToUpper(text)
+			}
+		case Identifier:
+			if options && isOption(lexemes, i) {
+				text = strings.ToUpper(text)
+			}
+			text = formatName(text)
+		case Keyword, Type, Constraint:
+			text = strings.ToUpper(text)
+		}
+
+		switch {
+		case prev == EOF, prev == LeftParen, prev == Dot:
+		case lx.Token == Comma, lx.Token == RightParen, lx.Token == Semicolon, lx.Token == Dot:
+		default:
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+		prev = lx.Token
+	}
+
+	normalized = b.String()
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return h.Sum64(), normalized
+}
+
+// isOption reports whether lexemes[i] is an option name or a word value of an
+// option; the values of ttl, hash and range name attributes and keep their case
+func isOption(lexemes []Lexeme, i int) bool {
+	if i+1 < len(lexemes) && lexemes[i+1].Text == "=" {
+		return true
+	}
+	if i < 2 || lexemes[i-1].Text != "=" {
+		return false
+	}
+	switch name := lexemes[i-2]; strings.ToLower(name.Text) {
+	case "ttl", "hash", "range":
+		return false
+	default:
+		return name.Token == Identifier || name.Token == Constraint
+	}
+}
+
+// closing returns the index of the bracket or brace matching lexemes[i]
+func closing(lexemes []Lexeme, i int) int {
+	depth := 0
+	for ; i < len(lexemes); i++ {
+		switch lexemes[i].Token {
+		case LeftBracket, LeftBrace:
+			depth++
+		case RightBracket, RightBrace:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(lexemes) - 1
+}
+
+// closingSet returns the index of the second > closing the <<set>> at i
+func closingSet(lexemes []Lexeme, i int) int {
+	for i += 2; i < len(lexemes); i++ {
+		if lexemes[i].Text == ">" && i+1 < len(lexemes) && lexemes[i+1].Text == ">" {
+			return i + 1
+		}
+	}
+	return len(lexemes) - 1
+}
@@ -0,0 +1,80 @@
+package dsql
+
+import (
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	expected := "SELECT * FROM users WHERE id = ? AND name LIKE ?"
+
+	hash, normalized := Fingerprint(`SELECT * FROM users WHERE id = 1 AND name LIKE "a"`)
+	if normalized != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", normalized)
+	}
+
+	for _, source := range []string{
+		`select *   from users where id = 2 and name like 'b';`,
+		"select * -- everything\nfrom users\nwhere id = ? and name like ?",
+		`SELECT * FROM users /* by id */ WHERE id = -1.5 AND name LIKE $$c$$`,
+	} {
+		h, n := Fingerprint(source)
+		if h != hash || n != normalized {
+			t.Error("expected ", hash, normalized)
+			t.Error("actual   ", h, n)
+		}
+	}
+
+	other, _ := Fingerprint(`SELECT * FROM users WHERE id = 1 OR name LIKE "a"`)
+	if other == hash {
+		t.Error("expected different hashes for AND and OR")
+	}
+}
+
+func TestFingerprintLists(t *testing.T) {
+	cases := map[string]string{
+		`insert into t (a, b, c, d) values (<<1, 2>>, [1, [2]], {"k": [3]}, x'0a')`:   "INSERT INTO t (a, b, c, d) VALUES (?, ?, ?, ?)",
+		"update `my-table` set tags = <<\"a\">> where id between (1, 5)":              "UPDATE `my-table` SET tags = ? WHERE id BETWEEN (?, ?)",
+		"create table t (id string hash) with (read = 5); drop table t":               "CREATE TABLE t (id STRING HASH) WITH (READ = ?); DROP TABLE t",
+		"select * from information_schema . tables where table_name = 'users'":        "SELECT * FROM information_schema.tables WHERE table_name = ?",
+		"create index i on t (Email hash) with (projection = keys_only)":              "CREATE INDEX i on t (Email HASH) WITH (PROJECTION = KEYS_ONLY)",
+		"CREATE INDEX i on t (Email HASH) WITH (PROJECTION = KEYS_ONLY)":              "CREATE INDEX i on t (Email HASH) WITH (PROJECTION = KEYS_ONLY)",
+		"create table t (Id string hash, index i with (hash = Id, projection = all))": "CREATE TABLE t (Id STRING HASH, INDEX i WITH (HASH = Id, PROJECTION = ALL))",
+	}
+
+	for source, expected := range cases {
+		_, actual := Fingerprint(source)
+		if actual != expected {
+			t.Error("expected ", expected)
+			t.Error("actual   ", actual)
+		}
+	}
+}
+
+func TestFingerprintOptions(t *testing.T) {
+	expected := "CREATE TABLE t (id STRING HASH) WITH (BILLING = PROVISIONED, READ = ?, TTL = expires_at)"
+
+	hash, normalized := Fingerprint("create table t (id string hash) with (BILLING = provisioned, read = 5, ttl = expires_at)")
+	if normalized != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", normalized)
+	}
+
+	h, n := Fingerprint("create table t (id string hash) with (billing = PROVISIONED, Read = 1, TTL = expires_at)")
+	if h != hash || n != normalized {
+		t.Error("expected ", hash, normalized)
+		t.Error("actual   ", h, n)
+	}
+
+	other, _ := Fingerprint("create table t (id string hash) with (billing = provisioned, read = 5, ttl = Expires_At)")
+	if other == hash {
+		t.Error("expected different hashes for different ttl attributes")
+	}
+
+	expected = "UPDATE t SET name = ? WHERE id = ?; ALTER TABLE t SET BILLING = PAY_PER_REQUEST"
+	_, normalized = Fingerprint("update t set name = 'a' where id = 1; alter table t set billing = pay_per_request")
+	if normalized != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", normalized)
+	}
+}
@@ -0,0 +1,274 @@
+// Print statements as canonical SQL
+package dsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format prints stmt with upper case keywords, one clause per line and
+// identifiers quoted only where needed; Parse(Format(stmt)) gives back stmt
+func Format(stmt Stmt) string {
+	var lines []string
+
+	switch s := stmt.(type) {
+	case *SelectStmt:
+		cols := "*"
+		if s.Columns != nil {
+			cols = formatNames(s.Columns)
+		}
+		from := formatName(s.Table)
+		if s.Schema != "" {
+			from = formatName(s.Schema) + "." + from
+		}
+		lines = append(lines, "SELECT "+cols, "FROM "+from)
+		lines = append(lines, formatWhere(s.Where)...)
+		if s.OrderBy != "" {
+			order := "ORDER BY " + formatName(s.OrderBy)
+			if s.Direction != "" {
+				order += " " + strings.ToUpper(s.Direction)
+			}
+			lines = append(lines, order)
+		}
+		if s.Limit > 0 {
+			lines = append(lines, "LIMIT "+strconv.Itoa(s.Limit))
+		}
+	case *InsertStmt:
+		var values []string
+		for _, v := range s.Values {
+			values = append(values, v.literal())
+		}
+		lines = append(lines,
+			"INSERT INTO "+formatName(s.Table)+" ("+formatNames(s.Columns)+")",
+			"VALUES ("+strings.Join(values, ", ")+")",
+		)
+	case *UpdateStmt:
+		lines = append(lines, "UPDATE "+formatName(s.Table))
+		for i, exp := range s.Set {
+			line := "    " + formatExpression(exp)
+			if i == 0 {
+				line = "SET " + formatExpression(exp)
+			}
+			if i < len(s.Set)-1 {
+				line += ","
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, formatWhere(s.Where)...)
+	case *DeleteStmt:
+		lines = append(lines, "DELETE FROM "+formatName(s.Table))
+		lines = append(lines, formatWhere(s.Where)...)
+	case *CreateTableStmt:
+		var elems []string
+		for _, def := range s.Definitions {
+			elem := formatName(def.Identifier) + " " + strings.ToUpper(def.Type)
+			if def.Constraint != "" {
+				elem += " " + strings.ToUpper(def.Constraint)
+			}
+			elems = append(elems, elem)
+		}
+		for _, idx := range s.Indexes {
+			elems = append(elems, formatIndex(idx))
+		}
+
+		create := "CREATE TABLE "
+		if s.IfNotExists {
+			create += "IF NOT EXISTS "
+		}
+		lines = append(lines, create+formatName(s.Table)+" (")
+		for i, elem := range elems {
+			if i < len(elems)-1 {
+				elem += ","
+			}
+			lines = append(lines, "  "+elem)
+		}
+		lines = append(lines, ")")
+		if len(s.Options) > 0 {
+			lines = append(lines, "WITH ("+formatOptions(s.Options)+")")
+		}
+		if s.Wait {
+			lines = append(lines, "WAIT")
+		}
+	case *DropTableStmt:
+		drop := "DROP TABLE "
+		if s.IfExists {
+			drop += "IF EXISTS "
+		}
+		lines = append(lines, drop+formatName(s.Table))
+		if s.Wait {
+			lines = append(lines, "WAIT")
+		}
+	case *AlterTableStmt:
+		var changes []string
+		if len(s.Options) > 0 {
+			changes = append(changes, "("+formatOptions(s.Options)+")")
+		}
+		for _, idx := range s.Indexes {
+			changes = append(changes, "INDEX "+formatName(idx.Name)+" ("+formatOptions(idx.Options)+")")
+		}
+		switch {
+		case s.TTL != "":
+			changes = append(changes, "TTL ON "+formatName(s.TTL))
+		case s.TTLOff:
+			changes = append(changes, "TTL OFF")
+		}
+
+		lines = append(lines, "ALTER TABLE "+formatName(s.Table))
+		for i, change := range changes {
+			line := "    " + change
+			if i == 0 {
+				line = "SET " + change
+			}
+			if i < len(changes)-1 {
+				line += ","
+			}
+			lines = append(lines, line)
+		}
+	case *CreateIndexStmt:
+		var keys []string
+		for _, key := range s.Keys {
+			k := formatName(key.Identifier)
+			if key.Type != "" {
+				k += " " + strings.ToUpper(key.Type)
+			}
+			keys = append(keys, k+" "+strings.ToUpper(key.Constraint))
+		}
+		lines = append(lines, "CREATE INDEX "+formatName(s.Name)+" ON "+formatName(s.Table)+" ("+strings.Join(keys, ", ")+")")
+
+		opts := formatIndexOptions(IndexDefinition{
+			Projection: s.Projection,
+			Include:    s.Include,
+			Read:       s.Read,
+			Write:      s.Write,
+		})
+		if len(opts) > 0 {
+			lines = append(lines, "WITH ("+strings.Join(opts, ", ")+")")
+		}
+		if s.Wait {
+			lines = append(lines, "WAIT")
+		}
+	case *ShowTablesStmt:
+		lines = append(lines, "SHOW TABLES")
+	case *ShowCreateTableStmt:
+		lines = append(lines, "SHOW CREATE TABLE "+formatName(s.Table))
+	case *DescribeStmt:
+		lines = append(lines, "DESCRIBE "+formatName(s.Table))
+	case *TruncateStmt:
+		lines = append(lines, "TRUNCATE TABLE "+formatName(s.Table))
+	case *DropIndexStmt:
+		lines = append(lines, "DROP INDEX "+formatName(s.Name)+" ON "+formatName(s.Table))
+		if s.Wait {
+			lines = append(lines, "WAIT")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatIndex(idx IndexDefinition) string {
+	opts := []string{"HASH = " + formatName(idx.Hash)}
+	if idx.Range != "" {
+		opts = append(opts, "RANGE = "+formatName(idx.Range))
+	}
+	opts = append(opts, formatIndexOptions(idx)...)
+
+	index := "INDEX " + formatName(idx.Name) + " WITH (" + strings.Join(opts, ", ") + ")"
+	if idx.Scope != "" {
+		index = strings.ToUpper(idx.Scope) + " " + index
+	}
+	return index
+}
+
+// projection and throughput
+func formatIndexOptions(idx IndexDefinition) (opts []string) {
+	switch idx.Projection {
+	case "all", "keys_only":
+		opts = append(opts, "PROJECTION = "+strings.ToUpper(idx.Projection))
+	case "include":
+		opts = append(opts, "PROJECTION = ("+formatNames(idx.Include)+")")
+	}
+	if idx.Read > 0 {
+		opts = append(opts, "READ = "+strconv.Itoa(idx.Read))
+	}
+	if idx.Write > 0 {
+		opts = append(opts, "WRITE = "+strconv.Itoa(idx.Write))
+	}
+	return opts
+}
+
+// option names and word values are upper cased, except a ttl attribute
+func formatOptions(opts []Option) string {
+	var list []string
+	for _, opt := range opts {
+		name := strings.ToUpper(opt.Name)
+		value := formatValue(opt.Value)
+		if name != "TTL" && isWord(opt.Value) {
+			value = strings.ToUpper(value)
+		}
+		list = append(list, formatName(name)+" = "+value)
+	}
+	return strings.Join(list, ", ")
+}
+
+// option values that lex as a number or a name are printed bare
+func formatValue(v string) string {
+	l := NewLexer(strings.NewReader(v))
+	if t := l.Peek(); (t == Number || t == Identifier) && l.Text() == v && l.Next() == EOF {
+		return v
+	}
+	return quoteString(v)
+}
+
+// isWord reports whether v lexes as a single identifier
+func isWord(v string) bool {
+	l := NewLexer(strings.NewReader(v))
+	return l.Peek() == Identifier && l.Text() == v && l.Next() == EOF
+}
+
+// WHERE on its own line, every further AND or OR indented below it
+func formatWhere(e Expr) (lines []string) {
+	conjs, exps := flatten(e)
+	for i, exp := range exps {
+		if i == 0 {
+			lines = append(lines, "WHERE "+formatExpression(exp))
+		} else {
+			lines = append(lines, "  "+strings.ToUpper(conjs[i])+" "+formatExpression(exp))
+		}
+	}
+	return lines
+}
+
+func formatExpression(exp Expression) string {
+	name := formatName(exp.Identifier)
+	switch exp.Operator {
+	case "between":
+		return name + " BETWEEN (" + exp.Value.literal() + ", " + exp.Between.literal() + ")"
+	case "like":
+		return name + " LIKE " + exp.Value.literal()
+	}
+	return name + " " + exp.Operator + " " + exp.Value.literal()
+}
+
+func formatNames(names []string) string {
+	var quoted []string
+	for _, name := range names {
+		quoted = append(quoted, formatName(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// names that wouldn't lex as a single identifier get backticks
+func formatName(name string) string {
+	if _, ok := words[strings.ToLower(name)]; ok {
+		return "`" + name + "`"
+	}
+	for i, r := range name {
+		if !isLetter(r) && (i == 0 || !isDigit(r)) {
+			return "`" + name + "`"
+		}
+	}
+	if name == "" {
+		return "``"
+	}
+	return name
+}
@@ -0,0 +1,138 @@
+package dsql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	source := "select id, `user-id`, `status`, name from users where id = 1 and n between (1, 5) or name like 'a' order by id asc limit 10"
+	expected := "SELECT id, `user-id`, status, name\n" +
+		"FROM users\n" +
+		"WHERE id = 1\n" +
+		"  AND n BETWEEN (1, 5)\n" +
+		"  OR name LIKE \"a\"\n" +
+		"ORDER BY id ASC\n" +
+		"LIMIT 10"
+
+	stmt, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := Format(stmt)
+	if actual != expected {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	source = "alter table users set billing = provisioned, (read = 5, write = 2), index by_email (read = 1)"
+	expected = "ALTER TABLE users\n" +
+		"SET (BILLING = PROVISIONED, READ = 5, WRITE = 2),\n" +
+		"    INDEX by_email (READ = 1)"
+
+	stmt, err = Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual = Format(stmt)
+	if actual != expected {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	source = "create table messages (group string hash, id number range, tags stringset) with (read = 10, write = 5)"
+	expected = "CREATE TABLE messages (\n" +
+		"  group STRING HASH,\n" +
+		"  id NUMBER RANGE,\n" +
+		"  tags STRINGSET\n" +
+		")\n" +
+		"WITH (READ = 10, WRITE = 5)"
+
+	stmt, err = Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual = Format(stmt)
+	if actual != expected {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, source := range []string{
+		"select * from users",
+		"select * from users limit 10 order by id",
+		"select `select`, `a.b` from `my-table` where `user-id` = -1.5e3 and `from` >= 2 order by id desc",
+		"select a from t where a <= 1 or b < 2 and c > 3 and d like \"x\"",
+		`insert into t (a, b, c, d, e, f, g, h, i, j) values (
+			"say \"hi\"\n", 'it''s', $$raw
+			line$$, "", true, null, x'0aff', ["x", 1, []], {"k": false, "m": {}}, <<"a", "b">>
+		)`,
+		"insert into t (a, b) values (<<1, 2>>, <<x'01', x'02'>>)",
+		"update users set name = \"b\", `status` = 2, tags = <<\"x\">> where id = 1 and created = 5",
+		"delete from users where id = 1 and created = 5",
+		"create table t (id string hash) WITH (READ=1, WRITE=1)",
+		"create table `my-table` (`user-id` binary hash, `range` numberset)",
+		"create table t (id string hash, n number range, a string, index i with (hash = id, range = n, projection = (x, `y-z`)), global index g with (projection = keys_only, hash = a, write = 2, read = 1), local index l with (hash = id, range = a, projection = all))",
+		"create table t (local string hash, global number range)",
+		"create index by_email on users (email string hash, `on` range) with (projection = (a, b), read = 1, write = 2) wait",
+		"create index i on wait (a hash)",
+		"drop index by_email on users",
+		"drop index by_email on users wait",
+		"drop table users",
+		"drop table `drop`",
+		"create table if not exists t (id string hash) with (read = 1, write = 1) wait",
+		"drop table if exists t wait",
+		"drop table `if`",
+		"create table t (id string hash) with (billing = pay_per_request, class = 'standard', `ttl` = \"a b\")",
+		"alter table t set read = 5",
+		"create table t (id string hash) with (read = 5, write = 5, billing = provisioned)",
+		"alter table t set (stream = new_image)",
+		"alter table t set Stream = New_Image, index i (Read = 2)",
+		"show tables",
+		"show create table `my-table`",
+		"select table_name from information_schema.tables where item_count > 5 order by table_name desc",
+		"select * from `information_schema`.`key_columns`",
+		"describe users",
+		"describe table `my-table`",
+		"alter table t set (read = 5, write = 2), index i (write = 1), index `j-k` (read = 3)",
+		"alter table sessions set ttl on expires_at",
+		"alter table sessions set read = 1, ttl off",
+		"alter table sessions set ttl on `ttl`",
+		"create table sessions (id string hash) with (ttl = expires_at)",
+		"truncate table sessions",
+		"truncate `my-table`",
+	} {
+		stmt, err := Parse(source)
+		if err != nil {
+			t.Error(source, err)
+			continue
+		}
+
+		formatted := Format(stmt)
+		actual, err := Parse(formatted)
+		if err != nil {
+			t.Error(formatted, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, stmt) {
+			t.Error("source  ", source)
+			t.Error("expected", stmt)
+			t.Error("actual  ", actual)
+		}
+
+		expected, experr := Compile(stmt)
+		request, err := Compile(actual)
+		if fmt.Sprint(err) != fmt.Sprint(experr) || !reflect.DeepEqual(request, expected) {
+			t.Error("source  ", source)
+			t.Error("expected", expected, experr)
+			t.Error("actual  ", request, err)
+		}
+	}
+}
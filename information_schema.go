@@ -0,0 +1,359 @@
+// Read-only catalog tables computed from ListTables and DescribeTable
+package dsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+type schemaColumn struct {
+	name string
+	typ  string // S or N
+}
+
+// the tables of information_schema and their columns
+var informationSchema = map[string][]schemaColumn{
+	"tables": []schemaColumn{
+		schemaColumn{"table_name", "S"},
+		schemaColumn{"table_type", "S"},
+		schemaColumn{"table_status", "S"},
+		schemaColumn{"billing_mode", "S"},
+		schemaColumn{"read_capacity_units", "N"},
+		schemaColumn{"write_capacity_units", "N"},
+		schemaColumn{"item_count", "N"},
+		schemaColumn{"table_size_bytes", "N"},
+	},
+	"columns": []schemaColumn{
+		schemaColumn{"table_name", "S"},
+		schemaColumn{"column_name", "S"},
+		schemaColumn{"ordinal_position", "N"},
+		schemaColumn{"data_type", "S"},
+		schemaColumn{"is_nullable", "S"},
+		schemaColumn{"column_key", "S"},
+	},
+	"indexes": []schemaColumn{
+		schemaColumn{"table_name", "S"},
+		schemaColumn{"index_name", "S"},
+		schemaColumn{"index_type", "S"},
+		schemaColumn{"projection_type", "S"},
+		schemaColumn{"index_status", "S"},
+		schemaColumn{"read_capacity_units", "N"},
+		schemaColumn{"write_capacity_units", "N"},
+	},
+	"key_columns": []schemaColumn{
+		schemaColumn{"table_name", "S"},
+		schemaColumn{"index_name", "S"},
+		schemaColumn{"column_name", "S"},
+		schemaColumn{"key_type", "S"},
+		schemaColumn{"ordinal_position", "N"},
+	},
+}
+
+// A SchemaQuery is a SELECT on information_schema; it is answered by the
+// driver, filtering, sorting and limiting the rows in memory
+type SchemaQuery struct {
+	Table     string
+	Columns   []string
+	Where     Expr
+	OrderBy   string
+	Direction string // "desc", otherwise ascending
+	Limit     int
+}
+
+// names are case insensitive
+func compileSchemaQuery(s *SelectStmt) (Request, error) {
+	if strings.ToLower(s.Schema) != "information_schema" {
+		return nil, fmt.Errorf("dsql: unknown schema %s", s.Schema)
+	}
+
+	q := &SchemaQuery{
+		Table:     strings.ToLower(s.Table),
+		OrderBy:   strings.ToLower(s.OrderBy),
+		Direction: s.Direction,
+		Limit:     s.Limit,
+	}
+	if _, ok := informationSchema[q.Table]; !ok {
+		return nil, fmt.Errorf("dsql: unknown table information_schema.%s", s.Table)
+	}
+
+	used := append([]string{}, s.Columns...)
+	if s.OrderBy != "" {
+		used = append(used, s.OrderBy)
+	}
+	_, exps := flatten(s.Where)
+	for _, exp := range exps {
+		used = append(used, exp.Identifier)
+	}
+	for _, col := range used {
+		if q.column(strings.ToLower(col)) < 0 {
+			return nil, fmt.Errorf("dsql: information_schema.%s has no column %s", q.Table, col)
+		}
+	}
+
+	for _, col := range s.Columns {
+		q.Columns = append(q.Columns, strings.ToLower(col))
+	}
+	q.Where = lowerNames(s.Where)
+	return q, nil
+}
+
+// lowerNames returns e with lower case attribute names
+func lowerNames(e Expr) Expr {
+	switch e := e.(type) {
+	case Expression:
+		e.Identifier = strings.ToLower(e.Identifier)
+		return e
+	case Logical:
+		return Logical{e.Operator, lowerNames(e.Left), lowerNames(e.Right)}
+	}
+	return e
+}
+
+// column returns the position of name in the table, or -1
+func (q *SchemaQuery) column(name string) int {
+	for i, col := range informationSchema[q.Table] {
+		if col.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// schema queries aren't sent to DynamoDB
+func (q *SchemaQuery) Rows(body io.ReadCloser) (driver.Rows, error) {
+	return nil, nil
+}
+
+// fetch describes the tables, only the one asked for when the condition
+// requires a table_name; missing tables have no rows
+func (q *SchemaQuery) fetch(list func() ([]string, error), describe func(string) (*TableDescription, error)) (*Rows, error) {
+	names := []string{}
+	if name, ok := q.tableName(); ok {
+		names = append(names, name)
+	} else {
+		var err error
+		if names, err = list(); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows [][]interface{}
+	for _, name := range names {
+		table, err := describe(name)
+		if isError(err, "ResourceNotFoundException") {
+			continue // no such table, or dropped since it was listed
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range schemaRows(q.Table, table) {
+			if q.Where == nil || q.match(q.Where, row) {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	if q.OrderBy != "" {
+		i := q.column(q.OrderBy)
+		sort.SliceStable(rows, func(a, b int) bool {
+			if q.Direction == "desc" {
+				a, b = b, a
+			}
+			return less(rows[a][i], rows[b][i])
+		})
+	}
+	if q.Limit > 0 && len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+	}
+
+	return q.rows(rows), nil
+}
+
+// tableName returns the table in a table_name = value condition that every
+// row has to meet
+func (q *SchemaQuery) tableName() (string, bool) {
+	conjs, exps := flatten(q.Where)
+	for _, conj := range conjs {
+		if conj == "or" {
+			return "", false
+		}
+	}
+	for _, exp := range exps {
+		if exp.Identifier == "table_name" && exp.Operator == "=" && exp.Value.Type() == "S" {
+			return *exp.Value.S, true
+		}
+	}
+	return "", false
+}
+
+// rows projects the selected columns
+func (q *SchemaQuery) rows(values [][]interface{}) *Rows {
+	cols := informationSchema[q.Table]
+	var idx []int
+	if q.Columns == nil {
+		for i := range cols {
+			idx = append(idx, i)
+		}
+	}
+	for _, col := range q.Columns {
+		idx = append(idx, q.column(col))
+	}
+
+	rows := &Rows{}
+	for _, i := range idx {
+		rows.cols = append(rows.cols, cols[i].name)
+		rows.types = append(rows.types, cols[i].typ)
+		rows.nullable = append(rows.nullable, false)
+	}
+	for _, v := range values {
+		row := make([]driver.Value, len(idx))
+		for j, i := range idx {
+			switch v := v[i].(type) {
+			case string:
+				row[j] = []byte(v)
+			default:
+				row[j] = v
+			}
+		}
+		rows.values = append(rows.values, row)
+	}
+	return rows
+}
+
+// match evaluates a condition the way DynamoDB would: LIKE is a prefix
+// match and values of another type never match
+func (q *SchemaQuery) match(e Expr, row []interface{}) bool {
+	switch e := e.(type) {
+	case Logical:
+		if e.Operator == "or" {
+			return q.match(e.Left, row) || q.match(e.Right, row)
+		}
+		return q.match(e.Left, row) && q.match(e.Right, row)
+	case Expression:
+		v := row[q.column(e.Identifier)]
+		if e.Operator == "like" {
+			s, ok := v.(string)
+			return ok && e.Value.Type() == "S" && strings.HasPrefix(s, *e.Value.S)
+		}
+
+		c, ok := compare(v, e.Value)
+		if !ok {
+			return false
+		}
+		switch e.Operator {
+		case "=":
+			return c == 0
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case ">=":
+			return c >= 0
+		case "between":
+			d, ok := compare(v, e.Between)
+			return ok && c >= 0 && d <= 0
+		}
+	}
+	return false
+}
+
+// compare orders a string or int64 column value and a literal of the same
+// type
+func compare(v interface{}, a Attribute) (int, bool) {
+	switch v := v.(type) {
+	case string:
+		if a.Type() == "S" {
+			return strings.Compare(v, *a.S), true
+		}
+	case int64:
+		n, ok := new(big.Rat).SetString(a.N)
+		if a.Type() == "N" && ok {
+			return new(big.Rat).SetInt64(v).Cmp(n), true
+		}
+	}
+	return 0, false
+}
+
+func less(a, b interface{}) bool {
+	switch a := a.(type) {
+	case string:
+		return a < b.(string)
+	case int64:
+		return a < b.(int64)
+	}
+	return false
+}
+
+// schemaRows lists the rows table contributes to information_schema.name,
+// with values in column order
+func schemaRows(name string, t *TableDescription) (rows [][]interface{}) {
+	switch name {
+	case "tables":
+		billing := "PROVISIONED"
+		if t.BillingModeSummary != nil {
+			billing = t.BillingModeSummary.BillingMode
+		}
+		read, write := capacity(t.ProvisionedThroughput)
+		rows = append(rows, []interface{}{
+			t.TableName, "BASE TABLE", t.TableStatus, billing,
+			read, write, t.ItemCount, t.TableSizeBytes,
+		})
+	case "columns":
+		// only table keys are on every item
+		for i, name := range t.attributes() {
+			key := keyType(t.KeySchema, name)
+			nullable := "YES"
+			if key != "" {
+				nullable = "NO"
+			}
+			rows = append(rows, []interface{}{
+				t.TableName, name, int64(i + 1),
+				typeNames[t.definition(name).AttributeType], nullable, key,
+			})
+		}
+	case "indexes":
+		for _, idx := range t.LocalSecondaryIndexes {
+			rows = append(rows, []interface{}{
+				t.TableName, idx.IndexName, "LOCAL", idx.Projection.ProjectionType,
+				"ACTIVE", int64(0), int64(0),
+			})
+		}
+		for _, idx := range t.GlobalSecondaryIndexes {
+			read, write := capacity(idx.ProvisionedThroughput)
+			rows = append(rows, []interface{}{
+				t.TableName, idx.IndexName, "GLOBAL", idx.Projection.ProjectionType,
+				idx.IndexStatus, read, write,
+			})
+		}
+	case "key_columns":
+		keys := func(index string, schema []Schema) {
+			for i, k := range schema {
+				rows = append(rows, []interface{}{
+					t.TableName, index, k.AttributeName, k.KeyType, int64(i + 1),
+				})
+			}
+		}
+		keys("PRIMARY", t.KeySchema)
+		for _, idx := range t.LocalSecondaryIndexes {
+			keys(idx.IndexName, idx.KeySchema)
+		}
+		for _, idx := range t.GlobalSecondaryIndexes {
+			keys(idx.IndexName, idx.KeySchema)
+		}
+	}
+	return rows
+}
+
+// zero for on-demand tables and indexes
+func capacity(t *Throughput) (read, write int64) {
+	if t == nil {
+		return 0, 0
+	}
+	return int64(t.ReadCapacityUnits), int64(t.WriteCapacityUnits)
+}
@@ -0,0 +1,181 @@
+package dsql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var schemaTables = map[string]*TableDescription{
+	"messages": &TableDescription{
+		TableName:   "messages",
+		TableStatus: "ACTIVE",
+		ItemCount:   12,
+		AttributeDefinitions: []AttributeDefinition{
+			AttributeDefinition{"created", "S"},
+			AttributeDefinition{"group", "S"},
+			AttributeDefinition{"id", "N"},
+		},
+		KeySchema: []Schema{Schema{"group", "HASH"}, Schema{"id", "RANGE"}},
+		LocalSecondaryIndexes: []LocalSecondaryIndex{
+			LocalSecondaryIndex{"by_created", []Schema{Schema{"group", "HASH"}, Schema{"created", "RANGE"}}, Projection{"KEYS_ONLY", nil}},
+		},
+		ProvisionedThroughput: &Throughput{10, 5},
+	},
+	"users": &TableDescription{
+		TableName:            "users",
+		TableStatus:          "ACTIVE",
+		AttributeDefinitions: []AttributeDefinition{AttributeDefinition{"id", "S"}, AttributeDefinition{"email", "S"}},
+		KeySchema:            []Schema{Schema{"id", "HASH"}},
+		GlobalSecondaryIndexes: []GlobalSecondaryIndex{
+			GlobalSecondaryIndex{
+				IndexName:   "by_email",
+				KeySchema:   []Schema{Schema{"email", "HASH"}},
+				Projection:  Projection{"ALL", nil},
+				IndexStatus: "CREATING",
+			},
+		},
+		BillingModeSummary: &BillingModeSummary{"PAY_PER_REQUEST"},
+	},
+}
+
+// run a query on schemaTables, returning the rows as strings and numbers
+func schemaQuery(source string) (cols []string, values [][]interface{}, listed bool, err error) {
+	req, err := parseRequest(source)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	list := func() ([]string, error) {
+		listed = true
+		return []string{"messages", "users"}, nil
+	}
+	describe := func(name string) (*TableDescription, error) {
+		if t, ok := schemaTables[name]; ok {
+			return t, nil
+		}
+		if name == "broken" {
+			return nil, errors.New("broken")
+		}
+		return nil, &DynamoError{"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException", "Requested resource not found: Table: " + name + " not found"}
+	}
+
+	rows, err := req.(*SchemaQuery).fetch(list, describe)
+	if err != nil {
+		return nil, nil, listed, err
+	}
+	for _, row := range rows.values {
+		var v []interface{}
+		for _, col := range row {
+			if b, ok := col.([]byte); ok {
+				v = append(v, string(b))
+			} else {
+				v = append(v, col)
+			}
+		}
+		values = append(values, v)
+	}
+	return rows.cols, values, listed, nil
+}
+
+func TestSchemaQueryTables(t *testing.T) {
+	cols, actual, _, err := schemaQuery("SELECT * FROM information_schema.tables")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCols := []string{"table_name", "table_type", "table_status", "billing_mode", "read_capacity_units", "write_capacity_units", "item_count", "table_size_bytes"}
+	if !reflect.DeepEqual(cols, expectedCols) {
+		t.Error("expected ", expectedCols)
+		t.Error("actual   ", cols)
+	}
+
+	expected := [][]interface{}{
+		[]interface{}{"messages", "BASE TABLE", "ACTIVE", "PROVISIONED", int64(10), int64(5), int64(12), int64(0)},
+		[]interface{}{"users", "BASE TABLE", "ACTIVE", "PAY_PER_REQUEST", int64(0), int64(0), int64(0), int64(0)},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected ", expected)
+		t.Error("actual   ", actual)
+	}
+}
+
+func TestSchemaQueryFilters(t *testing.T) {
+	cases := []struct {
+		source   string
+		expected [][]interface{}
+		listed   bool
+	}{
+		{
+			"select column_name, ordinal_position, is_nullable, column_key from information_schema.columns where table_name = 'messages'",
+			[][]interface{}{
+				[]interface{}{"group", int64(1), "NO", "HASH"},
+				[]interface{}{"id", int64(2), "NO", "RANGE"},
+				[]interface{}{"created", int64(3), "YES", ""},
+			},
+			false,
+		},
+		{
+			"SELECT Index_Name, index_type, projection_type FROM INFORMATION_SCHEMA.INDEXES ORDER BY index_name",
+			[][]interface{}{
+				[]interface{}{"by_created", "LOCAL", "KEYS_ONLY"},
+				[]interface{}{"by_email", "GLOBAL", "ALL"},
+			},
+			true,
+		},
+		{
+			"select index_name, column_name, ordinal_position from information_schema.key_columns where table_name = 'messages' and key_type = 'RANGE' or column_name like 'em' order by ordinal_position desc limit 2",
+			[][]interface{}{
+				[]interface{}{"PRIMARY", "id", int64(2)},
+				[]interface{}{"by_created", "created", int64(2)},
+			},
+			true,
+		},
+		{
+			"select table_name from information_schema.tables where item_count between (1, 20)",
+			[][]interface{}{[]interface{}{"messages"}},
+			true,
+		},
+		{
+			"select table_name from information_schema.tables where read_capacity_units >= 10 and table_name = 'users'",
+			nil,
+			false,
+		},
+		{
+			"select * from information_schema.columns where table_name = 'missing'",
+			nil,
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		_, actual, listed, err := schemaQuery(c.source)
+		if err != nil {
+			t.Error(c.source, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) || listed != c.listed {
+			t.Error("source   ", c.source)
+			t.Error("expected ", c.expected, c.listed)
+			t.Error("actual   ", actual, listed)
+		}
+	}
+}
+
+func TestSchemaQueryErrors(t *testing.T) {
+	for _, source := range []string{
+		"select * from other_schema.tables",
+		"select * from information_schema.views",
+		"select nope from information_schema.tables",
+		"select * from information_schema.tables where nope = 1",
+		"select * from information_schema.tables order by nope",
+	} {
+		if _, err := parseRequest(source); err == nil {
+			t.Error("expected error for", source)
+		}
+	}
+
+	if _, _, _, err := schemaQuery("select * from information_schema.tables where table_name = 'broken'"); err == nil {
+		t.Error("expected describe error")
+	}
+}
@@ -1,112 +1,365 @@
-// consider EOF and Semicolon equivalent - Terminator or something
+// Split statements into tokens; -- and /* */ comments are skipped
 package dsql
 
 import (
+	"bufio"
 	"io"
-	"regexp"
-	"strings"
-	"text/scanner"
+	"unicode"
+	"unicode/utf8"
 )
 
+// where a token starts in the source; Line and Column count from 1
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// A Lexeme is a token together with its text and position
+type Lexeme struct {
+	Token Token
+	Text  string
+	Pos   Position
+}
+
+// NewLexer reads tokens from source one at a time as the parser asks for them
 func NewLexer(source io.Reader) *Lexer {
-	l := &Lexer{}
-	l.pos = -1
-	l.scn.Init(source)
-	l.scan()
-	return l
+	rr, ok := source.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(source)
+	}
+	return &Lexer{src: rr, at: Position{0, 1, 1}}
 }
 
 type Lexer struct {
-	scn     scanner.Scanner
-	pos     int
-	tokens  []Token
-	strings []string
+	src     io.RuneReader
+	ahead   [3]rune // runes read from src but not consumed yet
+	n       int
+	at      Position // of the next rune
+	cur     Lexeme
+	started bool
+	buf     []byte // text of the token being scanned
+	low     []byte
 }
 
-func (l *Lexer) scan() {
-	var t Token
-	for t != EOF {
-		t = l.tokenize(l.sNext())
-		l.tokens = append(l.tokens, t)
-		l.strings = append(l.strings, l.sText())
+const eof = -1
+
+// canonical spelling of each word, so keywords don't allocate
+var canonical = map[string]string{}
+
+func init() {
+	for w := range words {
+		canonical[w] = w
 	}
 }
 
+// Peek returns the current token, scanning the first one if needed
 func (l *Lexer) Peek() Token {
-	if l.pos < 0 {
+	if !l.started {
 		l.Next()
 	}
-	return l.tokens[l.pos]
+	return l.cur.Token
 }
 
+// Next scans and returns the following token; it stays at EOF once reached
 func (l *Lexer) Next() Token {
-	l.pos++
-	return l.tokens[l.pos]
+	if l.started && l.cur.Token == EOF {
+		return EOF
+	}
+	l.started = true
+	l.buf = l.buf[:0]
+	if open, ok := l.skip(); !ok {
+		l.cur = Lexeme{Unknown, "/*", open}
+		return Unknown
+	}
+	pos := l.at
+	t, text := l.scan()
+	l.cur = Lexeme{t, text, pos}
+	return t
 }
 
+// Text of the current token: identifiers and literals keep their case,
+// everything else is lowercased
 func (l *Lexer) Text() string {
-	return l.strings[l.pos]
+	l.Peek()
+	return l.cur.Text
+}
+
+func (l *Lexer) Pos() Position {
+	l.Peek()
+	return l.cur.Pos
+}
+
+func (l *Lexer) Lexeme() Lexeme {
+	l.Peek()
+	return l.cur
 }
 
-func (l *Lexer) tokenize(r rune) (t Token) {
+func (l *Lexer) scan() (Token, string) {
+	r := l.peek(0)
+	switch {
+	case r == eof:
+		return EOF, ""
+	case (r == 'x' || r == 'X') && l.peek(1) == '\'':
+		l.take()
+		t, text := l.quoted(Binary)
+		if t == Binary {
+			text = text[2 : len(text)-1]
+		}
+		return t, text
+	case isLetter(r):
+		return l.word()
+	case isDigit(r) || r == '.' && isDigit(l.peek(1)):
+		return Number, l.number()
+	case r == '-' && (isDigit(l.peek(1)) || l.peek(1) == '.' && isDigit(l.peek(2))):
+		l.take()
+		return Number, l.number()
+	case r == '"':
+		return l.doubleQuoted()
+	case r == '\'':
+		return l.quoted(String)
+	case r == '`':
+		return l.backQuoted()
+	case r == '$' && l.peek(1) == '$':
+		return l.dollarQuoted()
+	}
+
+	l.take()
 	switch r {
-	case scanner.Ident:
-		if l.match(Keywords) {
-			t = Keyword
-		} else if l.match(Types) {
-			t = Type
-		} else if l.match(Constraints) {
-			t = Constraint
-		} else if l.match(Operators) {
-			t = Operator
-		} else {
-			t = Identifier
+	case '*':
+		return Wildcard, "*"
+	case ',':
+		return Comma, ","
+	case ';':
+		return Semicolon, ";"
+	case '(':
+		return LeftParen, "("
+	case ')':
+		return RightParen, ")"
+	case '[':
+		return LeftBracket, "["
+	case ']':
+		return RightBracket, "]"
+	case '{':
+		return LeftBrace, "{"
+	case '}':
+		return RightBrace, "}"
+	case ':':
+		return Colon, ":"
+	case '.':
+		return Dot, "."
+	case '=':
+		return Operator, "="
+	case '<':
+		if l.peek(0) == '=' {
+			l.take()
+			return Operator, "<="
 		}
-	case scanner.Float, scanner.Int:
-		t = Number
-	case scanner.String:
-		t = String
-	case scanner.EOF:
-		t = EOF
-	default:
-		if l.match(Operators) {
-			t = Operator
-		} else {
-			switch l.sText() {
-			case "*":
-				t = Wildcard
-			case ",":
-				t = Comma
-			case ";":
-				t = EOF
-			case "(":
-				t = LeftParen
-			case ")":
-				t = RightParen
-			default:
-				t = Unknown
+		return Operator, "<"
+	case '>':
+		if l.peek(0) == '=' {
+			l.take()
+			return Operator, ">="
+		}
+		return Operator, ">"
+	}
+	return Unknown, string(l.buf)
+}
+
+// skip passes over whitespace and comments; a /* comment without an end
+// isn't ok, open is where it starts
+func (l *Lexer) skip() (open Position, ok bool) {
+	for {
+		r := l.peek(0)
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			l.read()
+		case r == '-' && l.peek(1) == '-':
+			for r != '\n' && r != eof {
+				l.read()
+				r = l.peek(0)
 			}
+		case r == '/' && l.peek(1) == '*':
+			open = l.at
+			l.read()
+			l.read()
+			for !(l.peek(0) == '*' && l.peek(1) == '/') {
+				if l.read() == eof {
+					return open, false
+				}
+			}
+			l.read()
+			l.read()
+		default:
+			return open, true
+		}
+	}
+}
+
+// word reads an identifier, keyword, type, constraint or word operator
+func (l *Lexer) word() (Token, string) {
+	for r := l.peek(0); isLetter(r) || isDigit(r); r = l.peek(0) {
+		l.take()
+	}
+
+	l.low = l.low[:0]
+	for _, b := range l.buf {
+		if 'A' <= b && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		l.low = append(l.low, b)
+	}
+	if t, ok := words[string(l.low)]; ok {
+		return t, canonical[string(l.low)]
+	}
+	return Identifier, string(l.buf)
+}
+
+// number reads digits with an optional fraction and exponent
+func (l *Lexer) number() string {
+	l.digits()
+	if l.peek(0) == '.' {
+		l.take()
+		l.digits()
+	}
+	if r := l.peek(0); r == 'e' || r == 'E' {
+		s := l.peek(1)
+		if isDigit(s) || (s == '+' || s == '-') && isDigit(l.peek(2)) {
+			l.take()
+			l.take()
+			l.digits()
+		}
+	}
+	return string(l.buf)
+}
+
+func (l *Lexer) digits() {
+	for isDigit(l.peek(0)) {
+		l.take()
+	}
+}
+
+// doubleQuoted reads a "..." literal with Go escapes, which the parser decodes
+func (l *Lexer) doubleQuoted() (Token, string) {
+	l.take()
+	for {
+		switch l.peek(0) {
+		case eof, '\n':
+			return Unknown, string(l.buf)
+		case '\\':
+			l.take()
+			if r := l.peek(0); r != eof && r != '\n' {
+				l.take()
+			}
+		case '"':
+			l.take()
+			return String, string(l.buf)
+		default:
+			l.take()
+		}
+	}
+}
+
+// quoted reads a '...' literal, a doubled quote is escaped
+func (l *Lexer) quoted(t Token) (Token, string) {
+	l.take()
+	for {
+		r := l.peek(0)
+		if r == eof {
+			return Unknown, string(l.buf)
+		}
+		l.take()
+		if r == '\'' {
+			if l.peek(0) != '\'' {
+				return t, string(l.buf)
+			}
+			l.take()
+		}
+	}
+}
+
+// backQuoted reads a `quoted identifier`
+func (l *Lexer) backQuoted() (Token, string) {
+	l.take()
+	for {
+		r := l.peek(0)
+		if r == eof {
+			return Unknown, string(l.buf)
+		}
+		l.take()
+		if r == '`' {
+			return Identifier, string(l.buf[1 : len(l.buf)-1])
 		}
 	}
-	return t
 }
 
-func (l *Lexer) sNext() rune {
-	return l.scn.Scan()
+// dollarQuoted reads a raw $$...$$ string, which may span lines
+func (l *Lexer) dollarQuoted() (Token, string) {
+	l.take()
+	l.take()
+	for {
+		r := l.peek(0)
+		if r == eof {
+			return Unknown, string(l.buf)
+		}
+		l.take()
+		if r == '$' && l.peek(0) == '$' {
+			l.take()
+			return String, string(l.buf)
+		}
+	}
 }
 
-func (l *Lexer) sText() string {
-	return strings.ToLower(l.scn.TokenText())
+// peek returns the i-th rune after the current one without consuming it
+func (l *Lexer) peek(i int) rune {
+	for l.n <= i {
+		r, _, err := l.src.ReadRune()
+		if err != nil {
+			return eof
+		}
+		l.ahead[l.n] = r
+		l.n++
+	}
+	return l.ahead[i]
 }
 
-func (l *Lexer) match(s string) bool {
-	matched, err := regexp.MatchString(s, l.sText())
-	if err != nil {
-		return false
+// read consumes a rune and advances the position
+func (l *Lexer) read() rune {
+	r := l.peek(0)
+	if r == eof {
+		return eof
 	}
-	return matched
+	copy(l.ahead[:], l.ahead[1:l.n])
+	l.n--
+
+	l.at.Offset += utf8.RuneLen(r)
+	if r == '\n' {
+		l.at.Line++
+		l.at.Column = 1
+	} else {
+		l.at.Column++
+	}
+	return r
+}
+
+// take consumes a rune into the token text
+func (l *Lexer) take() {
+	r := l.read()
+	switch {
+	case r == eof:
+	case r < utf8.RuneSelf:
+		l.buf = append(l.buf, byte(r))
+	default:
+		var b [utf8.UTFMax]byte
+		l.buf = append(l.buf, b[:utf8.EncodeRune(b[:], r)]...)
+	}
+}
+
+func isLetter(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r == '_' ||
+		r >= utf8.RuneSelf && unicode.IsLetter(r)
 }
 
-func (l *Lexer) is(s string) bool {
-	return l.sText() == s
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
 }
@@ -20,7 +20,13 @@ func TestLexerCases(t *testing.T) {
 		},
 		Case{
 			`SELECT * FROM users;`,
-			[]Token{K, W, K, I, E},
+			[]Token{K, W, K, I, Semicolon, E},
+		},
+		Case{
+			`-- all users
+			SELECT * /* every column */ FROM users; -- done
+			DROP TABLE users`,
+			[]Token{K, W, K, I, Semicolon, K, K, I, E},
 		},
 		Case{
 			`SELECT * FROM users LIMIT 10`,
@@ -30,6 +36,10 @@ func TestLexerCases(t *testing.T) {
 			`SELECT * FROM users LIMIT 10 ORDER BY id ASC`,
 			[]Token{K, W, K, I, K, N, K, K, I, K, E},
 		},
+		Case{
+			`SELECT * FROM information_schema.tables WHERE n = .5`,
+			[]Token{K, W, K, I, Dot, I, K, I, O, N, E},
+		},
 		Case{
 			`SELECT id, name FROM users`,
 			[]Token{K, I, C, I, K, I, E},
@@ -78,12 +88,13 @@ func TestLexerCases(t *testing.T) {
 		},
 	}
 
-	var l *Lexer
 	var expected, actual []Token
 
 	for _, c := range cases {
-		l = NewLexer(strings.NewReader(c.source))
-		actual = l.tokens
+		actual = nil
+		for _, lx := range lexAll(c.source) {
+			actual = append(actual, lx.Token)
+		}
 		expected = c.tokens
 
 		if !reflect.DeepEqual(actual, expected) {
@@ -123,3 +134,89 @@ func TestPeekNextText(t *testing.T) {
 		t.Error("expected EOF", Names[l.Peek()])
 	}
 }
+
+func TestLexerPreservesCase(t *testing.T) {
+	source := `SeLeCt createdAt FROM Users WHERE Email = "Alice@Example.com" AND n = 1E5`
+
+	expected := []string{"select", "createdAt", "from", "Users", "where", "Email", "=", `"Alice@Example.com"`, "and", "n", "=", "1E5", ""}
+
+	var actual []string
+	for _, lx := range lexAll(source) {
+		actual = append(actual, lx.Text)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestLexerPositions(t *testing.T) {
+	source := "SELECT *\n  FROM `user-id`, über -- done\nWHERE a >= -1.5e3"
+
+	expected := []Lexeme{
+		Lexeme{Keyword, "select", Position{0, 1, 1}},
+		Lexeme{Wildcard, "*", Position{7, 1, 8}},
+		Lexeme{Keyword, "from", Position{11, 2, 3}},
+		Lexeme{Identifier, "user-id", Position{16, 2, 8}},
+		Lexeme{Comma, ",", Position{25, 2, 17}},
+		Lexeme{Identifier, "über", Position{27, 2, 19}},
+		Lexeme{Keyword, "where", Position{41, 3, 1}},
+		Lexeme{Identifier, "a", Position{47, 3, 7}},
+		Lexeme{Operator, ">=", Position{49, 3, 9}},
+		Lexeme{Number, "-1.5e3", Position{52, 3, 12}},
+		Lexeme{EOF, "", Position{58, 3, 18}},
+	}
+
+	actual := lexAll(source)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestLexerStreams(t *testing.T) {
+	r := strings.NewReader("DROP TABLE users; DROP TABLE groups")
+
+	l := NewLexer(r)
+	if l.Peek() != Keyword || r.Len() == 0 {
+		t.Error("expected only the first token to be read, remaining", r.Len())
+	}
+}
+
+func lexAll(source string) (lexemes []Lexeme) {
+	l := NewLexer(strings.NewReader(source))
+	for l.Peek(); ; l.Next() {
+		lexemes = append(lexemes, l.Lexeme())
+		if l.Peek() == EOF {
+			return lexemes
+		}
+	}
+}
+
+var benchSources = []string{
+	`SELECT id, name FROM users WHERE id = 1 AND name BETWEEN("a", "z")`,
+	`INSERT INTO users (id, name) VALUES (1, "A")`,
+	`UPDATE users SET name = "B" WHERE name = "A"`,
+	`
+	CREATE TABLE messages (
+		group string HASH,
+		id number RANGE,
+		created string,
+		updated string,
+		INDEX created WITH (HASH=group, RANGE=created, PROJECTION=(id, created)),
+		INDEX updated WITH (HASH=group, RANGE=updated, PROJECTION=ALL)
+	)
+	WITH (READ=10, WRITE=10)`,
+}
+
+func BenchmarkLexer(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, source := range benchSources {
+			l := NewLexer(strings.NewReader(source))
+			for l.Peek(); l.Peek() != EOF; l.Next() {
+				_ = l.Text()
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package dsql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+)
+
+type ListTables struct {
+	ExclusiveStartTableName string `json:",omitempty"`
+	Limit                   int    `json:",omitempty"`
+}
+
+type ListTablesResponse struct {
+	TableNames             []string
+	LastEvaluatedTableName string
+}
+
+func (l *ListTables) decode(body io.Reader) (*ListTablesResponse, error) {
+	res := &ListTablesResponse{}
+	if err := json.NewDecoder(body).Decode(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// a single page, fetch gets them all
+func (l *ListTables) Rows(body io.ReadCloser) (driver.Rows, error) {
+	res, err := l.decode(body)
+	if err != nil {
+		return nil, err
+	}
+	return tableRows(res.TableNames), nil
+}
+
+func (l *ListTables) fetch(cl Client) (*Rows, error) {
+	names, err := l.names(cl)
+	if err != nil {
+		return nil, err
+	}
+	return tableRows(names), nil
+}
+
+// names follows LastEvaluatedTableName to the last page
+func (l *ListTables) names(cl Client) (names []string, err error) {
+	for {
+		body, err := cl.Post(l)
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := l.decode(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, page.TableNames...)
+
+		if page.LastEvaluatedTableName == "" {
+			break
+		}
+		l.ExclusiveStartTableName = page.LastEvaluatedTableName
+	}
+
+	l.ExclusiveStartTableName = ""
+	return names, nil
+}
+
+// one row per table, in the order DynamoDB lists them
+func tableRows(names []string) *Rows {
+	var values [][]string
+	for _, name := range names {
+		values = append(values, []string{name})
+	}
+	return stringRows([]string{"table"}, values)
+}
@@ -2,228 +2,564 @@
 package dsql
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 )
 
 var ErrSyntax = errors.New("parser: syntax error")
 
-func Parse(source string) (Request, error) {
-	parser := &Parser{
+// A SyntaxError locates the token the parser could not accept
+type SyntaxError struct {
+	Position
+	Token    string   // offending token text, empty at end of input
+	Expected []string // what would have been accepted instead
+	Message  string   // set when the token is well formed but invalid
+	source   string
+}
+
+func (e *SyntaxError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = "unexpected end of input"
+		if e.Token != "" {
+			msg = fmt.Sprintf("unexpected %q", e.Token)
+		}
+		if len(e.Expected) > 0 {
+			msg += ", expected " + alternatives(e.Expected)
+		}
+	}
+	return fmt.Sprintf("parser: syntax error at line %d, column %d: %s", e.Line, e.Column, msg)
+}
+
+// errors.Is(err, ErrSyntax) holds for every SyntaxError
+func (e *SyntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+// Caret returns the offending line with a ^ under the token
+func (e *SyntaxError) Caret() string {
+	lines := strings.Split(e.source, "\n")
+	if e.Line < 1 || e.Line > len(lines) {
+		return ""
+	}
+	line := lines[e.Line-1]
+
+	pad := []rune{}
+	for i, r := range []rune(line) {
+		if i >= e.Column-1 {
+			break
+		}
+		if r != '\t' {
+			r = ' '
+		}
+		pad = append(pad, r)
+	}
+	return line + "\n" + string(pad) + "^"
+}
+
+func alternatives(s []string) string {
+	if len(s) == 1 {
+		return s[0]
+	}
+	return strings.Join(s[:len(s)-1], ", ") + " or " + s[len(s)-1]
+}
+
+// Parse reads a single statement
+func Parse(source string) (Stmt, error) {
+	return newParser(source).Parse()
+}
+
+// ParseScript reads any number of statements separated by semicolons
+func ParseScript(source string) ([]Stmt, error) {
+	return newParser(source).Script()
+}
+
+func newParser(source string) *Parser {
+	return &Parser{
 		src: source,
 		lex: NewLexer(strings.NewReader(source)),
 	}
-	return parser.Parse()
 }
 
 type Parser struct {
 	src string
 	lex *Lexer
-	err error
 }
 
-func (p *Parser) Parse() (req Request, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = r.(error)
+func (p *Parser) Parse() (stmt Stmt, err error) {
+	defer p.recover(&err)
+
+	for p.token() == Semicolon {
+		p.consume()
+	}
+	stmt = p.statement()
+	for p.token() == Semicolon {
+		p.consume()
+	}
+	if p.token() != EOF {
+		p.fail("end of input")
+	}
+	return stmt, nil
+}
+
+func (p *Parser) Script() (stmts []Stmt, err error) {
+	defer p.recover(&err)
+
+	for {
+		for p.token() == Semicolon {
+			p.consume()
 		}
-	}()
 
-	switch p.token() {
-	case Keyword:
-		switch p.text() {
-		case "select":
-			req = p.Select()
-		case "insert":
-			req = p.Insert()
-		case "update":
-			req = p.Update()
-		case "create":
-			req = p.Create()
-		case "delete":
-			req = p.Delete()
-		case "drop":
-			req = p.Drop()
-		default:
-			p.err = ErrSyntax
+		if p.token() == EOF {
+			return stmts, nil
+		}
+
+		stmts = append(stmts, p.statement())
+
+		if p.token() != EOF {
+			p.match(Semicolon)
 		}
-	default:
-		p.err = ErrSyntax
 	}
+}
 
-	return req, p.err
+// recover turns a syntax error raised while parsing into the returned error,
+// any other panic is a bug and keeps going
+func (p *Parser) recover(err *error) {
+	switch r := recover().(type) {
+	case nil:
+	case *SyntaxError:
+		*err = r
+	default:
+		panic(r)
+	}
 }
 
-func (p *Parser) Select() Request {
-	query := &Query{}
-	query.ScanIndexForward = false // default id desc
-	p.columns(query)
-	p.from(query)
-	p.limit(query)
-	p.where(query)
-	p.limit(query)
-	return query
+var statements = []string{"select", "insert", "update", "create", "delete", "drop", "alter", "show", "describe", "truncate"}
+
+func (p *Parser) statement() (stmt Stmt) {
+	if p.token() != Keyword {
+		p.fail(statements...)
+	}
+
+	switch p.text() {
+	case "select":
+		stmt = p.Select()
+	case "insert":
+		stmt = p.Insert()
+	case "update":
+		stmt = p.Update()
+	case "create":
+		stmt = p.Create()
+	case "delete":
+		stmt = p.Delete()
+	case "drop":
+		stmt = p.Drop()
+	case "alter":
+		stmt = p.Alter()
+	case "show":
+		stmt = p.Show()
+	case "describe":
+		stmt = p.Describe()
+	case "truncate":
+		stmt = p.Truncate()
+	default:
+		p.fail(statements...)
+	}
+
+	return stmt
 }
 
-func (p *Parser) from(query *Query) {
+func (p *Parser) Select() *SelectStmt {
+	stmt := &SelectStmt{}
+	p.columns(stmt)
 	p.matchS(Keyword, "from")
-	query.TableName = p.match(Identifier)
+	stmt.Table = p.match(Identifier)
+	if p.token() == Dot {
+		p.consume()
+		stmt.Schema, stmt.Table = stmt.Table, p.match(Identifier)
+	}
+	p.limit(stmt)
+	stmt.Where = p.where()
+	p.order(stmt)
+	p.limit(stmt)
+	p.order(stmt)
+	return stmt
 }
 
-func (p *Parser) columns(query *Query) {
-	p.match(Keyword)
+func (p *Parser) columns(stmt *SelectStmt) {
+	p.matchS(Keyword, "select")
 
 	if p.token() == Wildcard {
 		p.consume()
-	} else {
-		query.AddColumn(p.match(Identifier))
+		return
+	}
 
-		for p.token() == Comma {
-			p.match(Comma)
-			id := p.text()
-			p.match(Identifier)
-			query.AddColumn(id)
-		}
+	stmt.Columns = p.identifiers()
+}
+
+// identifiers reads a comma separated list of at least one name
+func (p *Parser) identifiers() []string {
+	ids := []string{p.match(Identifier)}
+	for p.token() == Comma {
+		p.match(Comma)
+		ids = append(ids, p.match(Identifier))
 	}
+	return ids
 }
 
-func (p *Parser) limit(query *Query) {
+func (p *Parser) limit(stmt *SelectStmt) {
 	if p.token() == Keyword && p.text() == "limit" {
 		p.consume()
-		limit, err := strconv.Atoi(p.match(Number))
-		if err != nil {
-			panic(err)
+		limit, err := strconv.Atoi(p.text())
+		if p.token() != Number || err != nil || limit < 1 {
+			p.failf("limit must be a positive integer")
 		}
-		query.Limit = limit
+		p.consume()
+		stmt.Limit = limit
 	}
 }
 
-func (p *Parser) where(query *Query) {
-	if p.token() == Keyword && p.text() == "where" {
+func (p *Parser) order(stmt *SelectStmt) {
+	if p.token() == Keyword && p.text() == "order" {
 		p.consume()
-		query.AddCondition(p.expr())
-		for p.token() == Operator {
-			p.match(Operator)
-			query.AddCondition(p.expr())
+		p.matchS(Keyword, "by")
+		stmt.OrderBy = p.match(Identifier)
+		if p.token() == Keyword && (p.text() == "asc" || p.text() == "desc") {
+			stmt.Direction = p.match(Keyword)
 		}
 	}
 }
 
-func (p *Parser) Insert() Request {
+// where reads an optional WHERE clause
+func (p *Parser) where() Expr {
+	if p.token() == Keyword && p.text() == "where" {
+		p.consume()
+		return p.condition()
+	}
+	return nil
+}
+
+// condition reads comparisons joined by AND or OR, left to right
+func (p *Parser) condition() Expr {
+	var e Expr = p.expr()
+	for p.token() == Operator && (p.text() == "and" || p.text() == "or") {
+		op := p.match(Operator)
+		e = Logical{op, e, p.expr()}
+	}
+	return e
+}
+
+func (p *Parser) Insert() *InsertStmt {
+	stmt := &InsertStmt{}
+
 	p.matchS(Keyword, "insert")
 	p.matchS(Keyword, "into")
 
-	table := p.match(Identifier)
+	stmt.Table = p.match(Identifier)
 
 	p.match(LeftParen)
-	columns := []string{p.match(Identifier)}
-	for p.token() == Comma {
-		p.match(Comma)
-		col := p.text()
-		p.match(Identifier)
-		columns = append(columns, col)
-	}
+	stmt.Columns = p.identifiers()
 	p.match(RightParen)
 
 	p.matchS(Keyword, "values")
 	p.match(LeftParen)
 
-	attrs := []Attribute{p.attribute()}
-	p.match(String, Number)
+	stmt.Values = []Attribute{p.value()}
 	for p.token() == Comma {
 		p.match(Comma)
-		attr := p.attribute()
-		p.match(String, Number)
-		attrs = append(attrs, attr)
+		stmt.Values = append(stmt.Values, p.value())
 	}
 
 	p.match(RightParen)
 
-	item := Item{}
-
-	for i, col := range columns {
-		item[col] = attrs[i]
-	}
-
-	return PutItem{TableName: table, Item: item}
+	return stmt
 }
 
-func (p *Parser) Update() Request {
-	update := UpdateItem{}
+func (p *Parser) Update() *UpdateStmt {
+	stmt := &UpdateStmt{}
 
 	p.matchS(Keyword, "update")
-	update.TableName = p.match(Identifier)
+	stmt.Table = p.match(Identifier)
 
 	p.matchS(Keyword, "set")
+	stmt.Set = p.assignments()
+
+	p.matchS(Keyword, "where")
+	stmt.Where = p.condition()
+
+	return stmt
+}
 
-	update.AddUpdate(p.expr())
+// assignments reads name = value pairs separated by commas
+func (p *Parser) assignments() []Expression {
+	list := []Expression{p.assignment()}
 	for p.token() == Comma {
 		p.match(Comma)
-		update.AddUpdate(p.expr())
+		list = append(list, p.assignment())
 	}
+	return list
+}
 
-	p.matchS(Keyword, "where")
+func (p *Parser) assignment() (exp Expression) {
+	exp.Identifier = p.match(Identifier)
+	exp.Operator = p.matchS(Operator, "=")
+	exp.Value = p.value()
+	return exp
+}
 
-	update.AddKey(p.expr())
-	for p.token() == Operator {
-		p.match(Operator)
-		update.AddKey(p.expr())
+func (p *Parser) Create() Stmt {
+	p.matchS(Keyword, "create")
+	if p.token() == Constraint && p.text() == "index" {
+		return p.createIndex()
 	}
-
-	return update
+	if p.token() != Keyword || p.text() != "table" {
+		p.fail("table", "index")
+	}
+	return p.createTable()
 }
 
-func (p *Parser) Create() Request {
-	create := CreateTable{}
+func (p *Parser) createTable() *CreateTableStmt {
+	stmt := &CreateTableStmt{}
 
-	p.matchS(Keyword, "create")
 	p.matchS(Keyword, "table")
-	create.TableName = p.match(Identifier)
+	if p.token() == Keyword && p.text() == "if" {
+		p.consume()
+		p.matchS(Keyword, "not")
+		p.matchS(Keyword, "exists")
+		stmt.IfNotExists = true
+	}
+	stmt.Table = p.match(Identifier)
 	p.match(LeftParen)
 
-	create.AddDefinition(p.definition())
+	p.element(stmt)
 	for p.token() == Comma {
 		p.match(Comma)
-		create.AddDefinition(p.definition())
+		p.element(stmt)
 	}
 
 	p.match(RightParen)
 
-	if p.token() == Keyword {
-		p.matchS(Keyword, "with")
+	if p.token() == Keyword && p.text() == "with" {
+		p.consume()
 		p.match(LeftParen)
-		create.AddThroughput(p.expr())
-		for p.token() == Comma {
-			p.match(Comma)
-			create.AddThroughput(p.expr())
-		}
+		stmt.Options = p.options()
 		p.match(RightParen)
 	}
 
-	return create
+	stmt.Wait = p.wait()
+	return stmt
 }
 
-func (p *Parser) Delete() Request {
+// options reads name = value pairs separated by commas
+func (p *Parser) options() []Option {
+	list := []Option{p.option()}
+	for p.token() == Comma {
+		p.match(Comma)
+		list = append(list, p.option())
+	}
+	return list
+}
+
+// option values are numbers, names or strings; the compiler checks them.
+// names and values are lower cased like keywords, except the attribute a ttl
+// option names
+func (p *Parser) option() (opt Option) {
+	opt.Name = strings.ToLower(p.match(Identifier))
+	p.matchS(Operator, "=")
+	switch p.token() {
+	case Number, Identifier:
+		opt.Value = p.text()
+		p.consume()
+	case String:
+		opt.Value = p.str()
+	default:
+		p.fail("number", "identifier", "string")
+	}
+	if opt.Name != "ttl" {
+		opt.Value = strings.ToLower(opt.Value)
+	}
+	return opt
+}
+
+func (p *Parser) Delete() *DeleteStmt {
+	stmt := &DeleteStmt{}
+
 	p.matchS(Keyword, "delete")
 	p.matchS(Keyword, "from")
-	deleteItem := DeleteItem{TableName: p.match(Identifier)}
+	stmt.Table = p.match(Identifier)
 	p.matchS(Keyword, "where")
-	deleteItem.AddKey(p.expr())
-	for p.token() == Operator {
-		p.matchS(Operator, "and")
-		deleteItem.AddKey(p.expr())
+	stmt.Where = p.condition()
+
+	return stmt
+}
+
+// CREATE INDEX name ON table (key [type] HASH, ...) [WITH (...)] [WAIT]
+func (p *Parser) createIndex() *CreateIndexStmt {
+	stmt := &CreateIndexStmt{}
+
+	p.matchS(Constraint, "index")
+	stmt.Name = p.match(Identifier)
+	p.matchWord("on")
+	stmt.Table = p.match(Identifier)
+
+	p.match(LeftParen)
+	stmt.Keys = []Definition{p.key()}
+	for p.token() == Comma {
+		p.match(Comma)
+		stmt.Keys = append(stmt.Keys, p.key())
+	}
+	p.match(RightParen)
+
+	if p.token() == Keyword && p.text() == "with" {
+		idx := IndexDefinition{}
+		p.consume()
+		p.match(LeftParen)
+		p.indexOption(&idx, createIndexOptions)
+		for p.token() == Comma {
+			p.match(Comma)
+			p.indexOption(&idx, createIndexOptions)
+		}
+		p.match(RightParen)
+		stmt.Projection, stmt.Include = idx.Projection, idx.Include
+		stmt.Read, stmt.Write = idx.Read, idx.Write
 	}
 
-	return deleteItem
+	stmt.Wait = p.wait()
+	return stmt
+}
+
+// a key of CREATE INDEX, the type may be left out for defined attributes
+func (p *Parser) key() (def Definition) {
+	def.Identifier = p.match(Identifier)
+	if p.token() == Type {
+		def.Type = p.match(Type)
+	}
+	if p.token() != Constraint || p.text() != "hash" && p.text() != "range" {
+		p.fail("type", "hash", "range")
+	}
+	def.Constraint = p.match(Constraint)
+	return def
 }
 
-func (p *Parser) Drop() Request {
+func (p *Parser) Drop() Stmt {
 	p.matchS(Keyword, "drop")
+	if p.token() == Constraint && p.text() == "index" {
+		p.consume()
+		stmt := &DropIndexStmt{Name: p.match(Identifier)}
+		p.matchWord("on")
+		stmt.Table = p.match(Identifier)
+		stmt.Wait = p.wait()
+		return stmt
+	}
+	if p.token() != Keyword || p.text() != "table" {
+		p.fail("table", "index")
+	}
+	p.consume()
+
+	stmt := &DropTableStmt{}
+	if p.token() == Keyword && p.text() == "if" {
+		p.consume()
+		p.matchS(Keyword, "exists")
+		stmt.IfExists = true
+	}
+	stmt.Table = p.match(Identifier)
+	stmt.Wait = p.wait()
+	return stmt
+}
+
+// ALTER TABLE table SET changes, where a change is name = value,
+// (name = value, ...), INDEX name (name = value, ...), TTL ON attribute or
+// TTL OFF
+func (p *Parser) Alter() *AlterTableStmt {
+	stmt := &AlterTableStmt{}
+
+	p.matchS(Keyword, "alter")
 	p.matchS(Keyword, "table")
-	return DeleteTable{p.match(Identifier)}
+	stmt.Table = p.match(Identifier)
+	p.matchS(Keyword, "set")
+
+	p.change(stmt)
+	for p.token() == Comma {
+		p.match(Comma)
+		p.change(stmt)
+	}
+
+	return stmt
+}
+
+func (p *Parser) change(stmt *AlterTableStmt) {
+	switch {
+	case p.token() == LeftParen:
+		p.consume()
+		stmt.Options = append(stmt.Options, p.options()...)
+		p.match(RightParen)
+	case p.token() == Constraint && p.text() == "index":
+		p.consume()
+		idx := AlterIndex{Name: p.match(Identifier)}
+		p.match(LeftParen)
+		idx.Options = p.options()
+		p.match(RightParen)
+		stmt.Indexes = append(stmt.Indexes, idx)
+	case p.isWord("ttl"):
+		p.consume()
+		if p.isWord("off") {
+			p.consume()
+			stmt.TTL, stmt.TTLOff = "", true
+			return
+		}
+		p.matchWord("on")
+		stmt.TTL, stmt.TTLOff = p.match(Identifier), false
+	case p.token() == Identifier:
+		stmt.Options = append(stmt.Options, p.option())
+	default:
+		p.fail("(", "index", "identifier")
+	}
+}
+
+// SHOW TABLES or SHOW CREATE TABLE table
+func (p *Parser) Show() Stmt {
+	p.matchS(Keyword, "show")
+	switch {
+	case p.isWord("tables"):
+		p.consume()
+		return &ShowTablesStmt{}
+	case p.token() == Keyword && p.text() == "create":
+		p.consume()
+		p.matchS(Keyword, "table")
+		return &ShowCreateTableStmt{p.match(Identifier)}
+	}
+	p.fail("tables", "create")
+	return nil
+}
+
+// DESCRIBE [TABLE] table
+func (p *Parser) Describe() *DescribeStmt {
+	p.matchS(Keyword, "describe")
+	if p.token() == Keyword && p.text() == "table" {
+		p.consume()
+	}
+	return &DescribeStmt{p.match(Identifier)}
+}
+
+// TRUNCATE [TABLE] table
+func (p *Parser) Truncate() *TruncateStmt {
+	p.matchS(Keyword, "truncate")
+	if p.token() == Keyword && p.text() == "table" {
+		p.consume()
+	}
+	return &TruncateStmt{p.match(Identifier)}
+}
+
+// wait reads an optional trailing WAIT
+func (p *Parser) wait() bool {
+	if p.isWord("wait") {
+		p.consume()
+		return true
+	}
+	return false
 }
 
 func (p *Parser) consume() Token {
@@ -235,18 +571,124 @@ func (p *Parser) token() Token {
 }
 
 func (p *Parser) text() string {
-	return strings.Trim(p.lex.Text(), `"`)
+	return p.lex.Text()
+}
+
+// str consumes a string literal and decodes it
+func (p *Parser) str() string {
+	if p.token() != String {
+		p.fail("string")
+	}
+	s, err := unquote(p.text())
+	if err != nil {
+		p.failf("invalid string literal: %v", err)
+	}
+	p.consume()
+	return s
 }
 
-func (p *Parser) attribute() (a Attribute) {
+// "double quoted" strings use Go escapes, 'single quoted' strings escape
+// a quote by doubling it and $$raw strings$$ are taken as is
+func unquote(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, "$$"):
+		return s[2 : len(s)-2], nil
+	case strings.HasPrefix(s, "'"):
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// literal values: strings, numbers, TRUE/FALSE, NULL, x'0a', [lists],
+// {"maps": 1} and <<sets>>
+func (p *Parser) value() (a Attribute) {
 	switch p.token() {
 	case String:
-		a.S = p.text()
+		s := p.str()
+		a.S = &s
 	case Number:
-		a.N = p.text()
+		a.N = p.match(Number)
+	case Boolean:
+		b := p.match(Boolean) == "true"
+		a.BOOL = &b
+	case Null:
+		p.match(Null)
+		a.NULL = true
+	case Binary:
+		b, err := hex.DecodeString(p.text())
+		if err != nil {
+			p.failf("invalid binary literal: %v", err)
+		}
+		p.consume()
+		a.B = b
+	case LeftBracket:
+		p.match(LeftBracket)
+		a.L = []Attribute{}
+		if p.token() != RightBracket {
+			a.L = append(a.L, p.value())
+			for p.token() == Comma {
+				p.match(Comma)
+				a.L = append(a.L, p.value())
+			}
+		}
+		p.match(RightBracket)
+	case LeftBrace:
+		p.match(LeftBrace)
+		a.M = map[string]Attribute{}
+		if p.token() != RightBrace {
+			p.pair(a.M)
+			for p.token() == Comma {
+				p.match(Comma)
+				p.pair(a.M)
+			}
+		}
+		p.match(RightBrace)
+	case Operator:
+		if p.text() != "<" {
+			p.fail("value")
+		}
+		a = p.set()
 	default:
-		p.print()
-		panic("unknown attribute")
+		p.fail("value")
+	}
+	return a
+}
+
+func (p *Parser) pair(m map[string]Attribute) {
+	key := p.str()
+	p.match(Colon)
+	m[key] = p.value()
+}
+
+func (p *Parser) set() (a Attribute) {
+	p.matchS(Operator, "<")
+	p.matchS(Operator, "<")
+
+	// remember where each element starts to point at a mismatch
+	starts := []Lexeme{p.lex.Lexeme()}
+	elems := []Attribute{p.value()}
+	for p.token() == Comma {
+		p.match(Comma)
+		starts = append(starts, p.lex.Lexeme())
+		elems = append(elems, p.value())
+	}
+
+	p.matchS(Operator, ">")
+	p.matchS(Operator, ">")
+
+	for i, e := range elems {
+		switch {
+		case e.Type() == "S" && a.NS == nil && a.BS == nil:
+			a.SS = append(a.SS, *e.S)
+		case e.Type() == "N" && a.SS == nil && a.BS == nil:
+			a.NS = append(a.NS, e.N)
+		case e.Type() == "B" && a.SS == nil && a.NS == nil:
+			a.BS = append(a.BS, e.B)
+		default:
+			err := p.errorAt(starts[i])
+			err.Message = "set elements must all be strings, numbers or binaries"
+			panic(err)
+		}
 	}
 	return a
 }
@@ -259,7 +701,11 @@ func (p *Parser) match(tokens ...Token) (s string) {
 			return s
 		}
 	}
-	p.panic()
+	var names []string
+	for _, t := range tokens {
+		names = append(names, strings.ToLower(Names[t]))
+	}
+	p.fail(names...)
 	return s
 }
 
@@ -267,82 +713,181 @@ func (p *Parser) matchS(t Token, s string) string {
 	if p.token() == t && p.text() == s {
 		p.consume()
 	} else {
-		p.panic()
+		p.fail(s)
 	}
 
 	return s
 }
 
-func (p *Parser) panic() {
-	err := fmt.Errorf(
-		"parser: unexpected token '%s' (%s) in '%s'",
-		p.text(),
-		Names[p.token()],
-		p.src,
-	)
+// fail reports the current token as unexpected
+func (p *Parser) fail(expected ...string) {
+	err := p.error()
+	err.Expected = expected
+	if lx := p.lex.Lexeme(); lx.Token == Unknown && lx.Text == "/*" {
+		err.Message = "unterminated comment"
+	}
 	panic(err)
 }
 
-func (p *Parser) print() {
-	log.Printf("current token: %s (%s)", Names[p.token()], p.text())
+// failf reports the current token as invalid
+func (p *Parser) failf(format string, args ...interface{}) {
+	err := p.error()
+	err.Message = fmt.Sprintf(format, args...)
+	panic(err)
 }
 
-type Expression struct {
-	Identifier  string
-	Operator    string
-	Token       Token
-	Text        string
-	BetweenText string
+func (p *Parser) error() *SyntaxError {
+	return p.errorAt(p.lex.Lexeme())
 }
 
-func (exp *Expression) Attribute() (a Attribute) {
-	return exp.newAttr(exp.Text)
+func (p *Parser) errorAt(lx Lexeme) *SyntaxError {
+	return &SyntaxError{Position: lx.Pos, Token: lx.Text, source: p.src}
 }
 
-func (exp *Expression) BetweenAttribute() (a Attribute) {
-	return exp.newAttr(exp.BetweenText)
-}
-
-func (exp *Expression) newAttr(s string) (a Attribute) {
-	switch exp.Token {
-	case String:
-		a = Attribute{S: s}
-	case Number:
-		a = Attribute{N: s}
-	}
-	return a
-}
+var operators = []string{"=", "<", "<=", ">", ">=", "like", "between"}
 
+// expr reads a single comparison
 func (p *Parser) expr() (exp Expression) {
 	exp.Identifier = p.match(Identifier)
+	if p.token() != Operator || p.text() == "and" || p.text() == "or" {
+		p.fail(operators...)
+	}
+	exp.Operator = p.match(Operator)
 
-	if p.text() == "between" {
-		exp.Operator = p.match(Operator)
+	if exp.Operator == "between" {
 		p.match(LeftParen)
-		exp.Token = p.token()
-		exp.Text = p.match(String, Number)
+		exp.Value = p.value()
 		p.match(Comma)
-		exp.BetweenText = p.match(String, Number)
+		exp.Between = p.value()
 		p.match(RightParen)
 	} else {
-		exp.Operator = p.match(Operator)
-		exp.Token = p.token()
-		exp.Text = p.match(String, Number)
+		exp.Value = p.value()
 	}
 	return exp
 }
 
-type Definition struct {
-	Identifier string
-	Type       string
-	Constraint string
+// element reads a column definition or an index of CREATE TABLE
+func (p *Parser) element(stmt *CreateTableStmt) {
+	if p.token() == Constraint && p.text() == "index" {
+		stmt.Indexes = append(stmt.Indexes, p.index(""))
+		return
+	}
+
+	name := p.match(Identifier)
+
+	// LOCAL and GLOBAL are only special in front of INDEX
+	if scope := strings.ToLower(name); p.token() == Constraint && p.text() == "index" {
+		if scope != "local" && scope != "global" {
+			p.fail("type")
+		}
+		stmt.Indexes = append(stmt.Indexes, p.index(scope))
+		return
+	}
+
+	stmt.Definitions = append(stmt.Definitions, p.definition(name))
 }
 
-func (p *Parser) definition() (def Definition) {
-	def.Identifier = p.match(Identifier)
+func (p *Parser) definition(name string) (def Definition) {
+	def.Identifier = name
 	def.Type = p.match(Type)
-	if p.token() == Constraint {
+	if p.token() == Constraint && (p.text() == "hash" || p.text() == "range") {
 		def.Constraint = p.match(Constraint)
 	}
 	return def
 }
+
+var indexOptions = []string{"hash", "range", "projection", "read", "write"}
+
+var createIndexOptions = []string{"projection", "read", "write"}
+
+// INDEX name WITH (HASH = a, RANGE = b, PROJECTION = ..., READ = 1, WRITE = 1)
+func (p *Parser) index(scope string) (idx IndexDefinition) {
+	idx.Scope = scope
+	p.matchS(Constraint, "index")
+	idx.Name = p.match(Identifier)
+	p.matchS(Keyword, "with")
+	p.match(LeftParen)
+
+	p.indexOption(&idx, indexOptions)
+	for p.token() == Comma {
+		p.match(Comma)
+		p.indexOption(&idx, indexOptions)
+	}
+
+	p.match(RightParen)
+	return idx
+}
+
+// indexOption reads one of the allowed options into idx
+func (p *Parser) indexOption(idx *IndexDefinition, allowed []string) {
+	option := strings.ToLower(p.text())
+	if p.token() != Constraint && p.token() != Identifier || !contains(allowed, option) {
+		p.fail(allowed...)
+	}
+	p.consume()
+	p.matchS(Operator, "=")
+
+	switch option {
+	case "hash":
+		idx.Hash = p.match(Identifier)
+	case "range":
+		idx.Range = p.match(Identifier)
+	case "projection":
+		p.projection(idx)
+	case "read":
+		idx.Read = p.units()
+	case "write":
+		idx.Write = p.units()
+	}
+}
+
+// PROJECTION = ALL, KEYS_ONLY or a list of attributes to include
+func (p *Parser) projection(idx *IndexDefinition) {
+	switch {
+	case p.token() == Constraint && p.text() == "all":
+		p.consume()
+		idx.Projection = "all"
+	case p.isWord("keys_only"):
+		p.consume()
+		idx.Projection = "keys_only"
+	case p.token() == LeftParen:
+		p.consume()
+		idx.Projection = "include"
+		idx.Include = p.identifiers()
+		p.match(RightParen)
+	default:
+		p.fail("all", "keys_only", "(")
+	}
+}
+
+// units reads a read or write capacity
+func (p *Parser) units() int {
+	n, err := strconv.Atoi(p.text())
+	if p.token() != Number || err != nil || n < 1 {
+		p.failf("throughput must be a positive integer")
+	}
+	p.consume()
+	return n
+}
+
+// isWord tells if the current token is an identifier that is a keyword in
+// this one place only, like ON or WAIT
+func (p *Parser) isWord(s string) bool {
+	return p.token() == Identifier && strings.ToLower(p.text()) == s
+}
+
+func (p *Parser) matchWord(s string) {
+	if !p.isWord(s) {
+		p.fail(s)
+	}
+	p.consume()
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
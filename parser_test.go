@@ -1,7 +1,9 @@
 package dsql
 
 import (
+	"errors"
 	"reflect"
+	"runtime"
 	"testing"
 )
 
@@ -9,12 +11,12 @@ func TestParseError(t *testing.T) {
 	var err error
 
 	_, err = Parse("oh hai frenz")
-	if err != ErrSyntax {
+	if !errors.Is(err, ErrSyntax) {
 		t.Error("unexpected ", err)
 	}
 
 	_, err = Parse("selects")
-	if err != ErrSyntax {
+	if !errors.Is(err, ErrSyntax) {
 		t.Error("unexpected ", err)
 	}
 
@@ -22,13 +24,236 @@ func TestParseError(t *testing.T) {
 	if err == nil {
 		t.Error("unexpected ", err)
 	}
+
+	_, err = Parse("drop table a; drop table b")
+	if err == nil {
+		t.Error("expected error for two statements")
+	}
+
+	_, err = Parse("drop table a b")
+	if err == nil {
+		t.Error("expected error for trailing tokens")
+	}
+}
+
+func TestParseRuntimeError(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(runtime.Error); !ok {
+			t.Error("expected runtime error to panic")
+		}
+	}()
+
+	var err error
+	func() {
+		defer newParser("drop table a").recover(&err)
+		var m map[string]int
+		m["a"] = 1
+	}()
+	t.Error("unexpected ", err)
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	_, err := Parse("select *\nfrom users\n\twhere id == 1")
+	e, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatal("unexpected ", err)
+	}
+	if e.Line != 3 || e.Column != 12 || e.Token != "=" {
+		t.Error("actual   ", e.Position, e.Token)
+	}
+	if !reflect.DeepEqual(e.Expected, []string{"value"}) {
+		t.Error("actual   ", e.Expected)
+	}
+
+	caret := "\twhere id == 1\n\t          ^"
+	if e.Caret() != caret {
+		t.Error("expected ", caret)
+		t.Error("actual   ", e.Caret())
+	}
+
+	_, err = Parse("select * from")
+	expected := "parser: syntax error at line 1, column 14: unexpected end of input, expected identifier"
+	if err == nil || err.Error() != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", err)
+	}
+
+	_, err = Parse("select * from users limit 0")
+	if e, ok := err.(*SyntaxError); !ok || e.Message != "limit must be a positive integer" {
+		t.Error("unexpected ", err)
+	}
+
+	_, err = Parse(`insert into t (s) values (<<"a", 1>>)`)
+	if e, ok := err.(*SyntaxError); !ok || e.Token != "1" {
+		t.Error("unexpected ", err)
+	}
+
+	_, err = Parse("create table t (id string hash, remote index i with (hash = id))")
+	if e, ok := err.(*SyntaxError); !ok || e.Token != "index" {
+		t.Error("unexpected ", err)
+	}
+
+	_, err = Parse("create table t (id string hash, index i with (hash = id, read = 0))")
+	if e, ok := err.(*SyntaxError); !ok || e.Message != "throughput must be a positive integer" {
+		t.Error("unexpected ", err)
+	}
+
+	_, err = Parse("select * from users where a = 1 and or b = 2")
+	if e, ok := err.(*SyntaxError); !ok || e.Token != "or" {
+		t.Error("unexpected ", err)
+	}
+
+	// a comment left open would swallow the rest of a script
+	for _, source := range []string{
+		"select * from t\n /* oops",
+		"select * from t\n /* oops */ /*",
+	} {
+		_, err = ParseScript(source + "\n; delete from t where id = 1")
+		e, ok := err.(*SyntaxError)
+		if !ok || e.Message != "unterminated comment" || e.Line != 2 {
+			t.Error("unexpected ", err)
+		}
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	source := `
+	-- schema
+	CREATE TABLE messages (id number hash);
+
+	/* seed data */
+	INSERT INTO messages (id) VALUES (1); -- first
+	INSERT INTO messages (id) VALUES (2);;
+	`
+	expected := []Stmt{
+		&CreateTableStmt{
+			Table:       "messages",
+			Definitions: []Definition{Definition{"id", "number", "hash"}},
+		},
+		&InsertStmt{"messages", []string{"id"}, []Attribute{Attribute{N: "1"}}},
+		&InsertStmt{"messages", []string{"id"}, []Attribute{Attribute{N: "2"}}},
+	}
+
+	actual, err := ParseScript(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseStatements(t *testing.T) {
+	source := `select id, name from users where id = 1 and n between (1, 5) or name like "a" order by id asc limit 5`
+	expected := &SelectStmt{
+		Columns: []string{"id", "name"},
+		Table:   "users",
+		Where: Logical{
+			"or",
+			Logical{
+				"and",
+				Expression{"id", "=", Attribute{N: "1"}, Attribute{}},
+				Expression{"n", "between", Attribute{N: "1"}, Attribute{N: "5"}},
+			},
+			Expression{"name", "like", Attribute{S: stringp("a")}, Attribute{}},
+		},
+		OrderBy:   "id",
+		Direction: "asc",
+		Limit:     5,
+	}
+
+	actual, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	source = `update users set name = "b", n = 2 where id = 1`
+	update := &UpdateStmt{
+		Table: "users",
+		Set: []Expression{
+			Expression{"name", "=", Attribute{S: stringp("b")}, Attribute{}},
+			Expression{"n", "=", Attribute{N: "2"}, Attribute{}},
+		},
+		Where: Expression{"id", "=", Attribute{N: "1"}, Attribute{}},
+	}
+
+	actual, err = Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, update) {
+		t.Error("expected", update)
+		t.Error("actual  ", actual)
+	}
+
+	source = `alter table users set billing = provisioned, (read = 5, write = 2), index by_email (read = 1)`
+	alter := &AlterTableStmt{
+		Table: "users",
+		Options: []Option{
+			Option{"billing", "provisioned"},
+			Option{"read", "5"},
+			Option{"write", "2"},
+		},
+		Indexes: []AlterIndex{
+			AlterIndex{"by_email", []Option{Option{"read", "1"}}},
+		},
+	}
+
+	actual, err = Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, alter) {
+		t.Error("expected", alter)
+		t.Error("actual  ", actual)
+	}
+
+	source = `alter table sessions set ttl on expires_at, ttl off`
+	alter = &AlterTableStmt{Table: "sessions", TTLOff: true}
+
+	actual, err = Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, alter) {
+		t.Error("expected", alter)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseSelectOrder(t *testing.T) {
+	for source, forward := range map[string]bool{
+		"select * from messages limit 10 order by id asc":  true,
+		"select * from messages order by id desc limit 10": false,
+	} {
+		actual, err := parseRequest(source)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		q := actual.(*Query)
+		if q.ScanIndexForward != forward || q.Limit != 10 {
+			t.Error("bad order", source, q)
+		}
+	}
 }
 
 func TestParseSelectWildcard(t *testing.T) {
 	source := "select * from messages;"
 	expected := &Query{TableName: "messages"}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -43,7 +268,7 @@ func TestParseSelectWithoutSemicolon(t *testing.T) {
 	source := "select * from messages"
 	expected := &Query{TableName: "messages"}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -57,11 +282,14 @@ func TestParseSelectWithoutSemicolon(t *testing.T) {
 func TestParseSelectColumns(t *testing.T) {
 	source := "select id, name from messages;"
 	expected := &Query{
-		TableName:       "messages",
-		AttributesToGet: []string{"id", "name"},
+		TableName:            "messages",
+		ProjectionExpression: "id, #n0",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{"#n0": "name"},
+		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -75,12 +303,15 @@ func TestParseSelectColumns(t *testing.T) {
 func TestParseSelectLimit(t *testing.T) {
 	source := "select id, name from messages limit 10;"
 	expected := &Query{
-		TableName:       "messages",
-		AttributesToGet: []string{"id", "name"},
-		Limit:           10,
+		TableName:            "messages",
+		ProjectionExpression: "id, #n0",
+		Limit:                10,
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{"#n0": "name"},
+		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -94,17 +325,18 @@ func TestParseSelectLimit(t *testing.T) {
 func TestParseSelectSingleCondition(t *testing.T) {
 	source := "select id, name from messages where id = 1;"
 	expected := &Query{
-		TableName:       "messages",
-		AttributesToGet: []string{"id", "name"},
-		KeyConditions: map[string]KeyCondition{
-			"id": KeyCondition{
-				ComparisonOperator: "EQ",
-				AttributeValueList: []Attribute{Attribute{N: "1"}},
+		TableName:              "messages",
+		ProjectionExpression:   "id, #n0",
+		KeyConditionExpression: "id = :v0",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{"#n0": "name"},
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{N: "1"},
 			},
 		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -118,21 +350,43 @@ func TestParseSelectSingleCondition(t *testing.T) {
 func TestParseSelectMultipleConditions(t *testing.T) {
 	source := `select id, name from messages where id = 1 AND name = "a";`
 	expected := &Query{
-		TableName:       "messages",
-		AttributesToGet: []string{"id", "name"},
-		KeyConditions: map[string]KeyCondition{
-			"id": KeyCondition{
-				ComparisonOperator: "EQ",
-				AttributeValueList: []Attribute{Attribute{N: "1"}},
+		TableName:              "messages",
+		ProjectionExpression:   "id, #n0",
+		KeyConditionExpression: "id = :v0 AND #n0 = :v1",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{"#n0": "name"},
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{N: "1"},
+				":v1": Attribute{S: stringp("a")},
 			},
-			"name": KeyCondition{
-				ComparisonOperator: "EQ",
-				AttributeValueList: []Attribute{Attribute{S: "a"}},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseSelectRangeCondition(t *testing.T) {
+	source := `select * from messages where id = 1 and created >= 5`
+	expected := &Query{
+		TableName:              "messages",
+		KeyConditionExpression: "id = :v0 AND created >= :v1",
+		Expressions: Expressions{
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{N: "1"},
+				":v1": Attribute{N: "5"},
 			},
 		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -146,21 +400,20 @@ func TestParseSelectMultipleConditions(t *testing.T) {
 func TestParseSelectMultipleConditionsOr(t *testing.T) {
 	source := `select id, name from messages where id = 1 AND name = "a" OR name = "b";`
 	expected := &Query{
-		TableName:       "messages",
-		AttributesToGet: []string{"id", "name"},
-		KeyConditions: map[string]KeyCondition{
-			"id": KeyCondition{
-				ComparisonOperator: "EQ",
-				AttributeValueList: []Attribute{Attribute{N: "1"}},
-			},
-			"name": KeyCondition{
-				ComparisonOperator: "EQ",
-				AttributeValueList: []Attribute{Attribute{S: "a"}, Attribute{S: "b"}},
+		TableName:              "messages",
+		ProjectionExpression:   "id, #n0",
+		KeyConditionExpression: "id = :v0 AND #n0 = :v1 OR #n0 = :v2",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{"#n0": "name"},
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{N: "1"},
+				":v1": Attribute{S: stringp("a")},
+				":v2": Attribute{S: stringp("b")},
 			},
 		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -174,21 +427,20 @@ func TestParseSelectMultipleConditionsOr(t *testing.T) {
 func TestParseSelectMultipleConditionsBetween(t *testing.T) {
 	source := `select id, name from messages where id = 1 AND name BETWEEN("a", "z");`
 	expected := &Query{
-		TableName:       "messages",
-		AttributesToGet: []string{"id", "name"},
-		KeyConditions: map[string]KeyCondition{
-			"id": KeyCondition{
-				ComparisonOperator: "EQ",
-				AttributeValueList: []Attribute{Attribute{N: "1"}},
-			},
-			"name": KeyCondition{
-				ComparisonOperator: "BETWEEN",
-				AttributeValueList: []Attribute{Attribute{S: "a"}, Attribute{S: "z"}},
+		TableName:              "messages",
+		ProjectionExpression:   "id, #n0",
+		KeyConditionExpression: "id = :v0 AND #n0 BETWEEN :v1 AND :v2",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{"#n0": "name"},
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{N: "1"},
+				":v1": Attribute{S: stringp("a")},
+				":v2": Attribute{S: stringp("z")},
 			},
 		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -205,11 +457,11 @@ func TestParseInsert(t *testing.T) {
 		TableName: "messages",
 		Item: Item{
 			"id":   Attribute{N: "1"},
-			"name": Attribute{S: "a"},
+			"name": Attribute{S: stringp("a")},
 		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -246,7 +498,7 @@ func TestParseCreate(t *testing.T) {
 		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -281,11 +533,10 @@ func TestParseCreateThroughput(t *testing.T) {
 				KeyType:       "RANGE",
 			},
 		},
+		ProvisionedThroughput: &Throughput{10, 5},
 	}
-	expected.ProvisionedThroughput.ReadCapacityUnits = 10
-	expected.ProvisionedThroughput.WriteCapacityUnits = 5
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -300,7 +551,7 @@ func TestDropTable(t *testing.T) {
 	source := `drop table messages;`
 	expected := DeleteTable{TableName: "messages"}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -317,11 +568,339 @@ func TestDeleteItem(t *testing.T) {
 		TableName: "messages",
 		Key: Item{
 			"id":   Attribute{N: "1"},
-			"name": Attribute{S: "a"},
+			"name": Attribute{S: stringp("a")},
 		},
 	}
 
-	actual, err := Parse(source)
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseInsertLiterals(t *testing.T) {
+	source := `insert into messages (a, b, c, d, e, f, g, h) values (
+		true, null, x'0a', ["x", 1], {"k": false}, <<"a", "b">>, <<1, 2>>, []
+	);`
+	yes, no := true, false
+	expected := PutItem{
+		TableName: "messages",
+		Item: Item{
+			"a": Attribute{BOOL: &yes},
+			"b": Attribute{NULL: true},
+			"c": Attribute{B: []byte{10}},
+			"d": Attribute{L: []Attribute{Attribute{S: stringp("x")}, Attribute{N: "1"}}},
+			"e": Attribute{M: map[string]Attribute{"k": Attribute{BOOL: &no}}},
+			"f": Attribute{SS: []string{"a", "b"}},
+			"g": Attribute{NS: []string{"1", "2"}},
+			"h": Attribute{L: []Attribute{}},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseMixedSet(t *testing.T) {
+	_, err := parseRequest(`insert into messages (a) values (<<"a", 1>>)`)
+	if err == nil {
+		t.Error("expected error for mixed set")
+	}
+}
+
+func TestParseInsertNegativeNumber(t *testing.T) {
+	source := `insert into messages (id, score) values (-1, -0.5);`
+	expected := PutItem{
+		TableName: "messages",
+		Item: Item{
+			"id":    Attribute{N: "-1"},
+			"score": Attribute{N: "-0.5"},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseMixedCaseSelect(t *testing.T) {
+	source := `SELECT userId, createdAt FROM Messages WHERE userId = "Alice@Example.com";`
+	expected := &Query{
+		TableName:              "Messages",
+		ProjectionExpression:   "userId, createdAt",
+		KeyConditionExpression: "userId = :v0",
+		Expressions: Expressions{
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{S: stringp("Alice@Example.com")},
+			},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseMixedCaseInsert(t *testing.T) {
+	source := `INSERT INTO Messages (userId, Body) VALUES ("Alice@Example.com", "Hello World");`
+	expected := PutItem{
+		TableName: "Messages",
+		Item: Item{
+			"userId": Attribute{S: stringp("Alice@Example.com")},
+			"Body":   Attribute{S: stringp("Hello World")},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseMixedCaseUpdate(t *testing.T) {
+	source := `UPDATE Messages SET Body = "Bye" WHERE userId = "Alice";`
+	expected := UpdateItem{
+		TableName: "Messages",
+		Key: Item{
+			"userId": Attribute{S: stringp("Alice")},
+		},
+		UpdateExpression: "SET Body = :v0",
+		Expressions: Expressions{
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{S: stringp("Bye")},
+			},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseMixedCaseCreate(t *testing.T) {
+	source := `CREATE TABLE Messages (userId STRING HASH, createdAt Number Range);`
+	expected := CreateTable{
+		TableName: "Messages",
+		AttributeDefinitions: []AttributeDefinition{
+			AttributeDefinition{
+				AttributeName: "userId",
+				AttributeType: "S",
+			},
+			AttributeDefinition{
+				AttributeName: "createdAt",
+				AttributeType: "N",
+			},
+		},
+		KeySchema: []Schema{
+			Schema{
+				AttributeName: "userId",
+				KeyType:       "HASH",
+			},
+			Schema{
+				AttributeName: "createdAt",
+				KeyType:       "RANGE",
+			},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseStringLiterals(t *testing.T) {
+	source := `insert into messages (a, b, c, d, e) values (
+		'it''s', "say \"hi\"\n", $$line one
+line "two"$$, "héllo ✓", 'ünïcödé'
+	);`
+	expected := PutItem{
+		TableName: "messages",
+		Item: Item{
+			"a": Attribute{S: stringp("it's")},
+			"b": Attribute{S: stringp("say \"hi\"\n")},
+			"c": Attribute{S: stringp("line one\nline \"two\"")},
+			"d": Attribute{S: stringp("héllo ✓")},
+			"e": Attribute{S: stringp("ünïcödé")},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseUnterminatedString(t *testing.T) {
+	for _, source := range []string{
+		`insert into messages (a) values ('abc)`,
+		`insert into messages (a) values ('abc'')`,
+		`insert into messages (a) values ($$abc)`,
+		`insert into messages (a) values ("abc)`,
+	} {
+		_, err := Parse(source)
+		if err == nil {
+			t.Error("expected error for", source)
+		}
+	}
+}
+
+func TestParseQuotedIdentifiers(t *testing.T) {
+	source := "select `user-id`, `order.total`, `select` from `my-table` where `user-id` = 1 and `order.total` like \"a\";"
+	expected := &Query{
+		TableName:              "my-table",
+		ProjectionExpression:   "#n0, #n1, #n2",
+		KeyConditionExpression: "#n0 = :v0 AND begins_with(#n1, :v1)",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{
+				"#n0": "user-id",
+				"#n1": "order.total",
+				"#n2": "select",
+			},
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{N: "1"},
+				":v1": Attribute{S: stringp("a")},
+			},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseQuotedIdentifiersUpdate(t *testing.T) {
+	source := "update `user-data` set `last-seen` = 5, `where` = \"x\" where `user-id` = \"a\""
+	expected := UpdateItem{
+		TableName: "user-data",
+		Key: Item{
+			"user-id": Attribute{S: stringp("a")},
+		},
+		UpdateExpression: "SET #n0 = :v0, #n1 = :v1",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{
+				"#n0": "last-seen",
+				"#n1": "where",
+			},
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{N: "5"},
+				":v1": Attribute{S: stringp("x")},
+			},
+		},
+	}
+
+	actual, err := parseRequest(source)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+}
+
+func TestParseQuotedIdentifiersInsertCreate(t *testing.T) {
+	actual, err := parseRequest("insert into `my-table` (`user-id`, `from`) values (1, 2)")
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := PutItem{
+		TableName: "my-table",
+		Item: Item{
+			"user-id": Attribute{N: "1"},
+			"from":    Attribute{N: "2"},
+		},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Error("expected", expected)
+		t.Error("actual  ", actual)
+	}
+
+	actual, err = parseRequest("create table `my-table` (`user-id` string hash)")
+	if err != nil {
+		t.Error(err)
+	}
+
+	create := actual.(CreateTable)
+	if create.TableName != "my-table" || create.KeySchema[0].AttributeName != "user-id" {
+		t.Error("bad create", create)
+	}
+}
+
+func TestParseReservedWords(t *testing.T) {
+	source := `select name, status, age from users where status = "a" and Date > 5`
+	expected := &Query{
+		TableName:              "users",
+		ProjectionExpression:   "#n0, #n1, age",
+		KeyConditionExpression: "#n1 = :v0 AND #n2 > :v1",
+		Expressions: Expressions{
+			ExpressionAttributeNames: map[string]string{
+				"#n0": "name",
+				"#n1": "status",
+				"#n2": "Date",
+			},
+			ExpressionAttributeValues: map[string]Attribute{
+				":v0": Attribute{S: stringp("a")},
+				":v1": Attribute{N: "5"},
+			},
+		},
+	}
+
+	actual, err := parseRequest(source)
 	if err != nil {
 		t.Error(err)
 	}
@@ -2,74 +2,148 @@ package dsql
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"io"
+	"strings"
+	"time"
 )
 
 type Item map[string]Attribute
 
-var ComparisonOperators = map[string]string{
-	"=":       "EQ",
-	"<":       "LT",
-	"<=":      "LE",
-	">":       "GT",
-	">=":      "GE",
-	"like":    "BEGINS_WITH",
-	"between": "BETWEEN",
+type Query struct {
+	TableName              string
+	ProjectionExpression   string `json:",omitempty"`
+	KeyConditionExpression string `json:",omitempty"`
+	FilterExpression       string `json:",omitempty"`
+	Expressions
+	ScanIndexForward  bool
+	Limit             int  `json:",omitempty"`
+	ExclusiveStartKey Item `json:",omitempty"`
+
+	orderBy string // ORDER BY attribute, has to be the range key
 }
 
-type KeyCondition struct {
-	ComparisonOperator string
-	AttributeValueList []Attribute
+func (q *Query) AddColumn(col string) {
+	q.ProjectionExpression = join(q.ProjectionExpression, ", ", q.Name(col))
 }
 
-type Query struct {
-	TableName        string
-	AttributesToGet  []string
-	KeyConditions    map[string]KeyCondition
-	ScanIndexForward bool
-	Limit            int
+// conj is the "and" or "or" joining exp to the previous condition
+func (q *Query) AddCondition(conj string, exp Expression) {
+	q.KeyConditionExpression = join(
+		q.KeyConditionExpression,
+		" "+strings.ToUpper(conj)+" ",
+		q.Condition(exp),
+	)
 }
 
-func (q *Query) AddColumn(col string) {
-	q.AttributesToGet = append(q.AttributesToGet, col)
+// SkipExpired filters out items whose time to live attribute is at or before
+// now; items without the attribute, or with one that isn't a number, never
+// expire and don't compare
+func (q *Query) SkipExpired(attr string, now time.Time) {
+	at, _ := NewAttribute(now.Unix())
+	q.FilterExpression = join(
+		q.FilterExpression,
+		" AND ",
+		"NOT "+q.Name(attr)+" <= "+q.Value(at),
+	)
 }
 
-func (q *Query) AddCondition(exp Expression) {
-	if q.KeyConditions == nil {
-		q.KeyConditions = map[string]KeyCondition{}
+// order checks that ORDER BY names the table's range key, the only order
+// DynamoDB can return items in
+func (q *Query) order(table *TableDescription) error {
+	if q.orderBy == "" {
+		return nil
+	}
+	for _, key := range table.KeySchema {
+		if key.KeyType == "RANGE" && key.AttributeName == q.orderBy {
+			return nil
+		}
 	}
+	return fmt.Errorf("dsql: %s can only be ordered by its range key, not %s", q.TableName, q.orderBy)
+}
 
-	var attrs []Attribute
+// the attribute names in ProjectionExpression
+func (q *Query) columns() (cols []string) {
+	if q.ProjectionExpression == "" {
+		return nil
+	}
+	for _, c := range strings.Split(q.ProjectionExpression, ", ") {
+		cols = append(cols, q.original(c))
+	}
+	return cols
+}
 
-	if _, ok := q.KeyConditions[exp.Identifier]; ok {
-		attrs = q.KeyConditions[exp.Identifier].AttributeValueList
-		attrs = append(attrs, exp.Attribute())
-	} else {
-		attrs = []Attribute{exp.Attribute()}
+func (q *Query) Rows(body io.ReadCloser) (driver.Rows, error) {
+	res, err := q.decode(body)
+	if err != nil {
+		return nil, err
 	}
+	return q.rows(res, nil), nil
+}
 
-	if exp.BetweenText != "" {
-		attr := exp.BetweenAttribute()
-		attrs = append(attrs, attr)
+// fetch follows LastEvaluatedKey until the result (or the limit) is
+// complete, so the column set is known before the first row is returned
+func (q *Query) fetch(cl Client, table *TableDescription) (*Rows, error) {
+	res := &QueryResponse{}
+
+	for {
+		body, err := cl.Post(q)
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := q.decode(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		res.Items = append(res.Items, page.Items...)
+		res.Count += page.Count
+
+		if page.LastEvaluatedKey == nil || (q.Limit > 0 && len(res.Items) >= q.Limit) {
+			break
+		}
+		q.ExclusiveStartKey = page.LastEvaluatedKey
 	}
 
-	q.KeyConditions[exp.Identifier] = KeyCondition{
-		ComparisonOperator: ComparisonOperators[exp.Operator],
-		AttributeValueList: attrs,
+	q.ExclusiveStartKey = nil
+
+	if q.Limit > 0 && len(res.Items) > q.Limit {
+		res.Items = res.Items[:q.Limit]
+		res.Count = q.Limit
 	}
+
+	return q.rows(res, table), nil
 }
 
-func (q *Query) Rows(body io.ReadCloser) (driver.Rows, error) {
+func (q *Query) decode(body io.Reader) (*QueryResponse, error) {
 	res := &QueryResponse{}
 	dec := ResponseDecoder{body, res}
 	err := dec.Decode()
 	if err != nil {
 		return nil, err
 	}
+	return res, nil
+}
+
+// key attributes come first, hash then range, when the table's layout is
+// known; table is nil otherwise
+func (q *Query) rows(res *QueryResponse, table *TableDescription) *Rows {
+	res.cols = q.columns()
+
+	var defs []AttributeDefinition
+	if table != nil {
+		defs = table.AttributeDefinitions
+		res.keys = table.keys()
+	}
+
+	rows := &Rows{cols: res.Columns(), values: res.Values(), types: res.Types(defs)}
 
-	if q.AttributesToGet != nil {
-		res.cols = q.AttributesToGet
+	// key attributes are on every item, anything else may be missing
+	for _, col := range rows.cols {
+		rows.nullable = append(rows.nullable, !contains(res.keys, col))
 	}
 
-	return &Rows{cols: res.Columns(), values: res.Values()}, nil
+	return rows
 }
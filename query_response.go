@@ -1,6 +1,9 @@
 package dsql
 
-import "database/sql/driver"
+import (
+	"database/sql/driver"
+	"sort"
+)
 
 type QueryResponse struct {
 	ConsumedCapacity struct {
@@ -12,9 +15,11 @@ type QueryResponse struct {
 	LastEvaluatedKey Item
 
 	cols []string
+	keys []string
 }
 
-// inspect all items because dynamo is schemaless
+// inspect all items because dynamo is schemaless; key attributes come
+// first and the rest are sorted so the order doesn't depend on map iteration
 func (q *QueryResponse) Columns() []string {
 	if q.cols != nil {
 		return q.cols
@@ -23,14 +28,26 @@ func (q *QueryResponse) Columns() []string {
 	ids := map[string]bool{}
 
 	for _, item := range q.Items {
-		for k, _ := range item {
-			if !ids[k] {
-				q.cols = append(q.cols, k)
-				ids[k] = true
-			}
+		for k := range item {
+			ids[k] = true
 		}
 	}
 
+	for _, k := range q.keys {
+		if ids[k] {
+			q.cols = append(q.cols, k)
+			delete(ids, k)
+		}
+	}
+
+	var rest []string
+	for k := range ids {
+		rest = append(rest, k)
+	}
+	sort.Strings(rest)
+
+	q.cols = append(q.cols, rest...)
+
 	return q.cols
 }
 
@@ -38,14 +55,43 @@ func (q *QueryResponse) Values() (values [][]driver.Value) {
 	for _, item := range q.Items {
 		row := make([]driver.Value, len(q.cols))
 		for j, col := range q.cols {
-			v := item[col].Value()
-			if v != nil {
-				row[j] = v
-			} else {
-				row[j] = []byte{}
-			}
+			row[j] = item[col].Value() // nil when missing or NULL
 		}
 		values = append(values, row)
 	}
 	return values
 }
+
+// DynamoDB type of each column: the attribute definition when known,
+// otherwise the type shared by all values ("" when they disagree)
+func (q *QueryResponse) Types(defs []AttributeDefinition) []string {
+	types := make([]string, len(q.Columns()))
+
+	for i, col := range q.cols {
+		for _, d := range defs {
+			if d.AttributeName == col {
+				types[i] = d.AttributeType
+			}
+		}
+		if types[i] != "" {
+			continue
+		}
+
+		mixed := false
+		for _, item := range q.Items {
+			t := item[col].Type()
+			switch {
+			case t == "" || t == "NULL":
+			case types[i] == "":
+				types[i] = t
+			case types[i] != t:
+				mixed = true
+			}
+		}
+		if mixed {
+			types[i] = ""
+		}
+	}
+
+	return types
+}
@@ -11,25 +11,69 @@ func TestQueryResponseColumns(t *testing.T) {
 		Items: []Item{
 			Item{
 				"id":    Attribute{N: "1"},
-				"email": Attribute{S: "a@example.com"},
+				"email": Attribute{S: stringp("a@example.com")},
 			},
 			Item{
 				"id":    Attribute{N: "1"},
-				"email": Attribute{S: "a@example.com"},
-				"bio":   Attribute{S: "person"},
+				"email": Attribute{S: stringp("a@example.com")},
+				"bio":   Attribute{S: stringp("person")},
 			},
 			Item{
-				"height": Attribute{S: "7'4"},
-				"weight": Attribute{S: "520 LB'"},
+				"height": Attribute{S: stringp("7'4")},
+				"weight": Attribute{S: stringp("520 LB'")},
 			},
 		},
 	}
 
-	expected := []string{"id", "email", "bio", "height", "weight"}
+	expected := []string{"bio", "email", "height", "id", "weight"}
 	actual := r.Columns()
 
 	if !reflect.DeepEqual(expected, actual) {
 		t.Error("expected ", expected)
 		t.Error("actual   ", actual)
 	}
+
+	r.cols = nil
+	r.keys = []string{"id", "weight"}
+
+	expected = []string{"id", "weight", "bio", "email", "height"}
+	actual = r.Columns()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Error("expected ", expected)
+		t.Error("actual   ", actual)
+	}
+}
+
+func TestQueryRowsKeys(t *testing.T) {
+	res := &QueryResponse{
+		Items: []Item{
+			Item{"id": Attribute{N: "1"}, "created": Attribute{N: "5"}, "body": Attribute{S: stringp("a")}},
+		},
+	}
+	table := &TableDescription{
+		KeySchema: []Schema{Schema{"created", "RANGE"}, Schema{"id", "HASH"}},
+	}
+
+	// hash, then range, whatever the conditions
+	q := &Query{}
+	q.AddCondition("", Expression{Identifier: "created", Operator: "=", Value: Attribute{N: "5"}})
+	q.AddCondition("and", Expression{Identifier: "id", Operator: "=", Value: Attribute{N: "1"}})
+	rows := q.rows(res, table)
+
+	expected := []string{"id", "created", "body"}
+	if !reflect.DeepEqual(rows.cols, expected) {
+		t.Error("expected ", expected)
+		t.Error("actual   ", rows.cols)
+	}
+
+	// without the layout nothing is known to be a key
+	res = &QueryResponse{Items: res.Items}
+	rows = (&Query{}).rows(res, nil)
+
+	expected = []string{"body", "created", "id"}
+	if !reflect.DeepEqual(rows.cols, expected) {
+		t.Error("expected ", expected)
+		t.Error("actual   ", rows.cols)
+	}
 }
@@ -2,15 +2,21 @@
 package dsql
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"io"
+	"reflect"
 )
 
 // driver implentation of driver.Rows interface
 type Rows struct {
-	values [][]driver.Value
-	cols   []string
-	idx    int
+	values     [][]driver.Value
+	cols       []string
+	idx        int
+	emptyNulls bool
+
+	types    []string // DynamoDB type per column, "" if unknown
+	nullable []bool
 }
 
 func (r *Rows) Columns() []string {
@@ -26,8 +32,110 @@ func (r *Rows) Next(dest []driver.Value) error {
 		return io.EOF
 	}
 	for i, v := range r.values[r.idx] {
+		if v == nil && r.emptyNulls {
+			v = []byte{}
+		}
 		dest[i] = v
 	}
 	r.idx++
 	return nil
 }
+
+// S, N, B, SS, NS, BS, BOOL, NULL, L or M
+func (r *Rows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.types) {
+		return r.types[i]
+	}
+	return ""
+}
+
+func (r *Rows) ColumnTypeNullable(i int) (nullable, ok bool) {
+	if i < len(r.nullable) {
+		return r.nullable[i], true
+	}
+	return false, false
+}
+
+var (
+	scanString     = reflect.TypeOf("")
+	scanInt64      = reflect.TypeOf(int64(0))
+	scanDecimal    = reflect.TypeOf(Decimal(""))
+	scanBool       = reflect.TypeOf(false)
+	scanBytes      = reflect.TypeOf([]byte(nil))
+	scanNullString = reflect.TypeOf(sql.NullString{})
+	scanNullInt64  = reflect.TypeOf(sql.NullInt64{})
+	scanNullBool   = reflect.TypeOf(sql.NullBool{})
+	scanInterface  = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// nullable columns get sql.Null* types so a missing attribute can be scanned
+func (r *Rows) ColumnTypeScanType(i int) reflect.Type {
+	nullable, _ := r.ColumnTypeNullable(i)
+
+	switch r.ColumnTypeDatabaseTypeName(i) {
+	case "S":
+		if nullable {
+			return scanNullString
+		}
+		return scanString
+	case "N":
+		decimal := false
+		for _, row := range r.values {
+			if _, ok := row[i].(string); ok {
+				decimal = true
+			}
+		}
+		switch {
+		case decimal && nullable:
+			return scanNullString
+		case decimal:
+			return scanDecimal
+		case nullable:
+			return scanNullInt64
+		}
+		return scanInt64
+	case "BOOL":
+		if nullable {
+			return scanNullBool
+		}
+		return scanBool
+	case "B", "SS", "NS", "BS", "L", "M":
+		return scanBytes
+	}
+	return scanInterface
+}
+
+// rows of a multi-statement query, one set per statement returning rows
+type resultSets struct {
+	*Rows
+	rest []*Rows
+}
+
+func (r *resultSets) HasNextResultSet() bool {
+	return len(r.rest) > 0
+}
+
+func (r *resultSets) NextResultSet() error {
+	if len(r.rest) == 0 {
+		return io.EOF
+	}
+	r.Rows, r.rest = r.rest[0], r.rest[1:]
+	return nil
+}
+
+// stringRows holds rows made by the driver itself, like SHOW TABLES
+func stringRows(cols []string, values [][]string) *Rows {
+	rows := &Rows{cols: cols}
+	for range cols {
+		rows.types = append(rows.types, "S")
+		rows.nullable = append(rows.nullable, false)
+	}
+	for _, v := range values {
+		row := make([]driver.Value, len(v))
+		for i, s := range v {
+			row[i] = []byte(s)
+		}
+		rows.values = append(rows.values, row)
+	}
+	return rows
+}
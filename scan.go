@@ -0,0 +1,54 @@
+package dsql
+
+import (
+	"database/sql/driver"
+	"io"
+)
+
+// Scan reads every item of a table, a page at a time
+type Scan struct {
+	TableName            string
+	ProjectionExpression string `json:",omitempty"`
+	FilterExpression     string `json:",omitempty"`
+	Expressions
+	ExclusiveStartKey Item `json:",omitempty"`
+}
+
+func (s *Scan) AddColumn(col string) {
+	s.ProjectionExpression = join(s.ProjectionExpression, ", ", s.Name(col))
+}
+
+func (s *Scan) Rows(body io.ReadCloser) (driver.Rows, error) {
+	res, err := s.decode(body)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{cols: res.Columns(), values: res.Values(), types: res.Types(nil)}, nil
+}
+
+func (s *Scan) decode(body io.Reader) (*QueryResponse, error) {
+	res := &QueryResponse{}
+	dec := ResponseDecoder{body, res}
+	err := dec.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// page fetches the next page and moves ExclusiveStartKey past it, to nil
+// after the last one
+func (s *Scan) page(cl Client) (*QueryResponse, error) {
+	body, err := cl.Post(s)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	res, err := s.decode(body)
+	if err != nil {
+		return nil, err
+	}
+	s.ExclusiveStartKey = res.LastEvaluatedKey
+	return res, nil
+}
@@ -0,0 +1,88 @@
+// Reverse engineer the DDL of a live table
+package dsql
+
+import (
+	"database/sql/driver"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ShowCreateTable is answered from DescribeTable and DescribeTimeToLive
+type ShowCreateTable struct {
+	TableName string
+}
+
+func (s ShowCreateTable) Rows(body io.ReadCloser) (driver.Rows, error) {
+	return nil, nil
+}
+
+// createStatement rebuilds the CREATE TABLE of a table with time to live on
+// the attribute ttl, if not empty; Format prints it
+func createStatement(t *TableDescription, ttl string) *CreateTableStmt {
+	stmt := &CreateTableStmt{Table: t.TableName}
+	for _, name := range t.attributes() {
+		stmt.Definitions = append(stmt.Definitions, Definition{
+			Identifier: name,
+			Type:       typeNames[t.definition(name).AttributeType],
+			Constraint: strings.ToLower(keyType(t.KeySchema, name)),
+		})
+	}
+
+	for _, idx := range t.LocalSecondaryIndexes {
+		stmt.Indexes = append(stmt.Indexes, indexDefinition("local", idx.IndexName, idx.KeySchema, idx.Projection))
+	}
+	for _, idx := range t.GlobalSecondaryIndexes {
+		def := indexDefinition("global", idx.IndexName, idx.KeySchema, idx.Projection)
+		def.Read, def.Write = provisioned(idx.ProvisionedThroughput)
+		stmt.Indexes = append(stmt.Indexes, def)
+	}
+
+	if t.BillingModeSummary != nil && t.BillingModeSummary.BillingMode == "PAY_PER_REQUEST" {
+		stmt.Options = append(stmt.Options, Option{"billing", "pay_per_request"})
+	} else if read, write := provisioned(t.ProvisionedThroughput); read > 0 {
+		stmt.Options = append(stmt.Options,
+			Option{"read", strconv.Itoa(read)},
+			Option{"write", strconv.Itoa(write)},
+		)
+	}
+	if t.TableClassSummary != nil && t.TableClassSummary.TableClass != "STANDARD" {
+		stmt.Options = append(stmt.Options, Option{"class", strings.ToLower(t.TableClassSummary.TableClass)})
+	}
+	if s := t.StreamSpecification; s != nil && s.StreamEnabled {
+		stmt.Options = append(stmt.Options, Option{"stream", strings.ToLower(s.StreamViewType)})
+	}
+	if ttl != "" {
+		stmt.Options = append(stmt.Options, Option{"ttl", ttl})
+	}
+	return stmt
+}
+
+// an index the way the parser reads it, with an explicit scope
+func indexDefinition(scope, name string, keys []Schema, p Projection) IndexDefinition {
+	return IndexDefinition{
+		Name:       name,
+		Scope:      scope,
+		Hash:       keyName(keys, "HASH"),
+		Range:      keyName(keys, "RANGE"),
+		Projection: strings.ToLower(p.ProjectionType),
+		Include:    p.NonKeyAttributes,
+	}
+}
+
+func keyName(keys []Schema, kind string) string {
+	for _, k := range keys {
+		if k.KeyType == kind {
+			return k.AttributeName
+		}
+	}
+	return ""
+}
+
+// on-demand tables and indexes report zero capacity
+func provisioned(t *Throughput) (read, write int) {
+	if t == nil {
+		return 0, 0
+	}
+	return t.ReadCapacityUnits, t.WriteCapacityUnits
+}
@@ -0,0 +1,112 @@
+package dsql
+
+import (
+	"reflect"
+	"testing"
+)
+
+var liveMessages = &TableDescription{
+	TableName: "messages",
+	AttributeDefinitions: []AttributeDefinition{
+		AttributeDefinition{"created", "S"},
+		AttributeDefinition{"group", "S"},
+		AttributeDefinition{"id", "N"},
+		AttributeDefinition{"author", "S"},
+	},
+	KeySchema: []Schema{Schema{"group", "HASH"}, Schema{"id", "RANGE"}},
+	LocalSecondaryIndexes: []LocalSecondaryIndex{
+		LocalSecondaryIndex{
+			IndexName:  "by_created",
+			KeySchema:  []Schema{Schema{"group", "HASH"}, Schema{"created", "RANGE"}},
+			Projection: Projection{"INCLUDE", []string{"body", "subject"}},
+		},
+	},
+	GlobalSecondaryIndexes: []GlobalSecondaryIndex{
+		GlobalSecondaryIndex{
+			IndexName:             "by_author",
+			KeySchema:             []Schema{Schema{"author", "HASH"}},
+			Projection:            Projection{"KEYS_ONLY", nil},
+			ProvisionedThroughput: &Throughput{5, 2},
+			IndexStatus:           "ACTIVE",
+		},
+	},
+	ProvisionedThroughput: &Throughput{10, 10},
+	TableClassSummary:     &TableClassSummary{"STANDARD"},
+	StreamSpecification:   &StreamSpecification{true, "NEW_IMAGE"},
+}
+
+func TestShowCreate(t *testing.T) {
+	expected := "CREATE TABLE messages (\n" +
+		"  group STRING HASH,\n" +
+		"  id NUMBER RANGE,\n" +
+		"  created STRING,\n" +
+		"  author STRING,\n" +
+		"  LOCAL INDEX by_created WITH (HASH = group, RANGE = created, PROJECTION = (body, subject)),\n" +
+		"  GLOBAL INDEX by_author WITH (HASH = author, PROJECTION = KEYS_ONLY, READ = 5, WRITE = 2)\n" +
+		")\n" +
+		"WITH (READ = 10, WRITE = 10, STREAM = NEW_IMAGE)"
+
+	stmt := createStatement(liveMessages, "")
+	actual := Format(stmt)
+	if actual != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", actual)
+	}
+
+	parsed, err := Parse(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, stmt) {
+		t.Error("expected ", stmt)
+		t.Error("actual   ", parsed)
+	}
+
+	// creates the same table again
+	req, err := Compile(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := req.(CreateTable)
+	if !reflect.DeepEqual(create.KeySchema, liveMessages.KeySchema) ||
+		!reflect.DeepEqual(create.LocalSecondaryIndexes, liveMessages.LocalSecondaryIndexes) ||
+		!reflect.DeepEqual(create.StreamSpecification, liveMessages.StreamSpecification) ||
+		len(create.AttributeDefinitions) != 4 ||
+		*create.ProvisionedThroughput != (Throughput{10, 10}) ||
+		*create.GlobalSecondaryIndexes[0].ProvisionedThroughput != (Throughput{5, 2}) {
+		t.Error("actual   ", create)
+	}
+}
+
+func TestShowCreateOnDemand(t *testing.T) {
+	table := &TableDescription{
+		TableName:             "sessions",
+		AttributeDefinitions:  []AttributeDefinition{AttributeDefinition{"id", "B"}},
+		KeySchema:             []Schema{Schema{"id", "HASH"}},
+		ProvisionedThroughput: &Throughput{0, 0},
+		BillingModeSummary:    &BillingModeSummary{"PAY_PER_REQUEST"},
+		TableClassSummary:     &TableClassSummary{"STANDARD_INFREQUENT_ACCESS"},
+		StreamSpecification:   &StreamSpecification{false, ""},
+	}
+
+	expected := "CREATE TABLE sessions (\n" +
+		"  id BINARY HASH\n" +
+		")\n" +
+		"WITH (BILLING = PAY_PER_REQUEST, CLASS = STANDARD_INFREQUENT_ACCESS, TTL = \"expires-at\")"
+
+	stmt := createStatement(table, "expires-at")
+	actual := Format(stmt)
+	if actual != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", actual)
+	}
+
+	parsed, err := Parse(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, stmt) {
+		t.Error("expected ", stmt)
+		t.Error("actual   ", parsed)
+	}
+}
@@ -11,17 +11,40 @@ type Statement struct {
 	args  []driver.Value
 }
 
-func (s *Statement) Prepare() string {
-	for _, v := range s.args {
-		s.query = strings.Replace(s.query, "?", s.quote(v), 1)
+// Prepare fills the ? placeholders with the arguments in order. Placeholders
+// are found with the lexer, so a ? in a comment, a string or an argument is
+// left alone; their number must match the arguments
+func (s *Statement) Prepare() (string, error) {
+	var holes []int // offsets
+	lex := NewLexer(strings.NewReader(s.query))
+	for lex.Peek() != EOF {
+		if lx := lex.Lexeme(); lx.Token == Unknown && lx.Text == "?" {
+			holes = append(holes, lx.Pos.Offset)
+		}
+		lex.Next()
 	}
-	return s.query
+	if len(holes) != len(s.args) {
+		return "", fmt.Errorf("dsql: %d placeholders but %d arguments", len(holes), len(s.args))
+	}
+
+	var out []string
+	last := 0
+	for i, v := range s.args {
+		lit, err := s.quote(v)
+		if err != nil {
+			return "", fmt.Errorf("dsql: argument %d: %w", i+1, err)
+		}
+		out = append(out, s.query[last:holes[i]], lit)
+		last = holes[i] + 1
+	}
+	s.query = strings.Join(append(out, s.query[last:]), "")
+	return s.query, nil
 }
 
-func (s *Statement) quote(v driver.Value) string {
-	switch v.(type) {
-	case string, []byte:
-		return fmt.Sprintf("\"%v\"", v)
+func (s *Statement) quote(v driver.Value) (string, error) {
+	a, err := NewAttribute(v)
+	if err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("%v", v)
+	return a.literal(), nil
 }
@@ -2,6 +2,7 @@ package dsql
 
 import (
 	"database/sql/driver"
+	"math"
 	"testing"
 )
 
@@ -12,10 +13,87 @@ func TestStatementPrepare(t *testing.T) {
 	}
 
 	expected := `INSERT INTO users (id, names) VALUES (1, "a")`
-	actual := stmt.Prepare()
+	actual, err := stmt.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if actual != expected {
 		t.Error("expected ", expected)
 		t.Error("actual   ", actual)
 	}
 }
+
+func TestStatementPrepareTypes(t *testing.T) {
+	stmt := &Statement{
+		"INSERT INTO users (a, b, c, d, e) VALUES (?, ?, ?, ?, ?)",
+		[]driver.Value{true, nil, []byte{10}, []string{"x", "y"}, []interface{}{1, "z"}},
+	}
+
+	expected := `INSERT INTO users (a, b, c, d, e) VALUES (TRUE, NULL, x'0a', <<"x", "y">>, [1, "z"])`
+	actual, err := stmt.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", actual)
+	}
+}
+
+func TestStatementPrepareEscapes(t *testing.T) {
+	stmt := &Statement{
+		"INSERT INTO users (id, bio) VALUES (?, ?)",
+		[]driver.Value{"a?", "say \"hi\"\nnaïve"},
+	}
+
+	query, err := stmt.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := parseRequest(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := req.(PutItem).Item
+	if *item["id"].S != "a?" || *item["bio"].S != "say \"hi\"\nnaïve" {
+		t.Error("bad round trip", item)
+	}
+}
+
+func TestStatementPrepareSkipsCommentsAndStrings(t *testing.T) {
+	stmt := &Statement{
+		"-- why?\nINSERT INTO t (a, b, c) /* ? */ VALUES (?, 'ok?', ?)",
+		[]driver.Value{1, "x"},
+	}
+
+	expected := "-- why?\nINSERT INTO t (a, b, c) /* ? */ VALUES (1, 'ok?', \"x\")"
+	actual, err := stmt.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != expected {
+		t.Error("expected ", expected)
+		t.Error("actual   ", actual)
+	}
+}
+
+func TestStatementPrepareCount(t *testing.T) {
+	for _, args := range [][]driver.Value{nil, []driver.Value{1, 2}} {
+		stmt := &Statement{"DELETE FROM t WHERE id = ?", args}
+		if _, err := stmt.Prepare(); err == nil {
+			t.Error("expected error for", len(args), "arguments")
+		}
+	}
+}
+
+func TestStatementPrepareBadArgument(t *testing.T) {
+	for _, v := range []driver.Value{[]string{}, math.NaN(), math.Inf(1), struct{}{}} {
+		stmt := &Statement{"INSERT INTO t (id, v) VALUES (1, ?)", []driver.Value{v}}
+		if query, err := stmt.Prepare(); err == nil {
+			t.Error("expected error for", v, "got", query)
+		}
+	}
+}
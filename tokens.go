@@ -2,6 +2,8 @@ package dsql
 
 type Token rune
 
+// Deprecated: words are looked up in a map by the lexer now, these patterns
+// are not used and don't cover newer words
 const (
 	Keywords    = "^(select|insert|create|update|delete|drop|from|where|set|limit|order|by|asc|desc|into|values|table|with)$"
 	Types       = "^(number|numberset|string|stringset)$"
@@ -9,6 +11,57 @@ const (
 	Operators   = "^(=|>|>=|<|<=|like|and|or|between)$"
 )
 
+// words of the dialect and the token they lex as, matched case-insensitively
+var words = map[string]Token{
+	"select":   Keyword,
+	"insert":   Keyword,
+	"create":   Keyword,
+	"update":   Keyword,
+	"delete":   Keyword,
+	"drop":     Keyword,
+	"alter":    Keyword,
+	"show":     Keyword,
+	"describe": Keyword,
+	"truncate": Keyword,
+	"from":     Keyword,
+	"where":    Keyword,
+	"set":      Keyword,
+	"limit":    Keyword,
+	"order":    Keyword,
+	"by":       Keyword,
+	"asc":      Keyword,
+	"desc":     Keyword,
+	"into":     Keyword,
+	"values":   Keyword,
+	"table":    Keyword,
+	"with":     Keyword,
+	"if":       Keyword,
+	"not":      Keyword,
+	"exists":   Keyword,
+
+	"number":    Type,
+	"numberset": Type,
+	"string":    Type,
+	"stringset": Type,
+	"binary":    Type,
+	"binaryset": Type,
+
+	"hash":       Constraint,
+	"range":      Constraint,
+	"index":      Constraint,
+	"all":        Constraint,
+	"projection": Constraint,
+
+	"like":    Operator,
+	"and":     Operator,
+	"or":      Operator,
+	"between": Operator,
+
+	"true":  Boolean,
+	"false": Boolean,
+	"null":  Null,
+}
+
 const (
 	Keyword Token = iota
 	Identifier
@@ -23,22 +76,42 @@ const (
 	RightParen
 	EOF
 	Unknown
+	Boolean
+	Null
+	Binary
+	LeftBracket
+	RightBracket
+	LeftBrace
+	RightBrace
+	Colon
+	Dot
+	Semicolon
 )
 
 var Names = map[Token]string{
-	Keyword:    "Keyword",
-	Identifier: "Identifier",
-	Constraint: "Constraint",
-	Wildcard:   "Wildcard",
-	Type:       "Type",
-	String:     "String",
-	Number:     "Number",
-	Operator:   "Operator",
-	Comma:      ",",
-	LeftParen:  "(",
-	RightParen: ")",
-	EOF:        "EOF",
-	Unknown:    "Unknown",
+	Keyword:      "Keyword",
+	Identifier:   "Identifier",
+	Constraint:   "Constraint",
+	Wildcard:     "Wildcard",
+	Type:         "Type",
+	String:       "String",
+	Number:       "Number",
+	Operator:     "Operator",
+	Comma:        ",",
+	LeftParen:    "(",
+	RightParen:   ")",
+	Boolean:      "Boolean",
+	Null:         "Null",
+	Binary:       "Binary",
+	LeftBracket:  "[",
+	RightBracket: "]",
+	LeftBrace:    "{",
+	RightBrace:   "}",
+	Colon:        ":",
+	Dot:          ".",
+	Semicolon:    ";",
+	EOF:          "EOF",
+	Unknown:      "Unknown",
 }
 
 func names(tokens []Token) (n []string) {
@@ -65,19 +138,29 @@ const (
 )
 
 var Symbols = map[Token]string{
-	Keyword:    "K",
-	Identifier: "I",
-	Constraint: "X",
-	Wildcard:   "W",
-	Type:       "T",
-	String:     "S",
-	Number:     "N",
-	Operator:   "O",
-	Comma:      "C",
-	LeftParen:  "L",
-	RightParen: "R",
-	EOF:        "E",
-	Unknown:    "U",
+	Keyword:      "K",
+	Identifier:   "I",
+	Constraint:   "X",
+	Wildcard:     "W",
+	Type:         "T",
+	String:       "S",
+	Number:       "N",
+	Operator:     "O",
+	Comma:        "C",
+	LeftParen:    "L",
+	RightParen:   "R",
+	Boolean:      "B",
+	Null:         "Z",
+	Binary:       "Y",
+	LeftBracket:  "[",
+	RightBracket: "]",
+	LeftBrace:    "{",
+	RightBrace:   "}",
+	Colon:        ":",
+	Dot:          ".",
+	Semicolon:    ";",
+	EOF:          "E",
+	Unknown:      "U",
 }
 
 func symbols(tokens []Token) (s []string) {
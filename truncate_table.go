@@ -0,0 +1,39 @@
+package dsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+)
+
+// TruncateTable deletes every item of a table, keeping the table; it is
+// carried out with Scan and BatchWriteItem
+type TruncateTable struct {
+	TableName string
+}
+
+func (t TruncateTable) Rows(body io.ReadCloser) (driver.Rows, error) {
+	return nil, nil
+}
+
+// run scans the table's keys a page at a time and deletes each page before
+// reading the next; it returns how many items were deleted, also on error
+func (t TruncateTable) run(ctx context.Context, cl Client, keys []string) (deleted int64, err error) {
+	scan := &Scan{TableName: t.TableName}
+	for _, k := range keys {
+		scan.AddColumn(k)
+	}
+
+	for {
+		page, err := scan.page(cl)
+		if err != nil {
+			return deleted, err
+		}
+
+		n, err := deleteKeys(ctx, cl, t.TableName, page.Items)
+		deleted += n
+		if err != nil || scan.ExclusiveStartKey == nil {
+			return deleted, err
+		}
+	}
+}
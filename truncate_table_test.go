@@ -0,0 +1,131 @@
+package dsql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTruncateTable(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = 0
+
+	// two pages of 30 and 10 keys; the first batch comes back half done once
+	page := func(from, to int, last bool) string {
+		var items []string
+		for i := from; i < to; i++ {
+			items = append(items, fmt.Sprintf(`{"id": {"N": "%d"}}`, i))
+		}
+		next := ``
+		if !last {
+			next = fmt.Sprintf(`, "LastEvaluatedKey": {"id": {"N": "%d"}}`, to-1)
+		}
+		return `{"Items": [` + strings.Join(items, ", ") + `]` + next + `}`
+	}
+
+	var (
+		mu      sync.Mutex
+		scans   []Scan
+		deletes int
+		retried bool
+	)
+	cl := MockClient{
+		OnPost: func(r Request) (io.ReadCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			body := `{}`
+			switch r := r.(type) {
+			case *Scan:
+				scans = append(scans, *r)
+				body = page(0, 30, false)
+				if r.ExclusiveStartKey != nil {
+					body = page(30, 40, true)
+				}
+			case BatchWriteItem:
+				batch := r.RequestItems["sessions"]
+				if len(batch) == 25 && !retried {
+					retried = true
+					deletes += 20
+					left, _ := json.Marshal(batch[20:])
+					body = `{"UnprocessedItems": {"sessions": ` + string(left) + `}}`
+				} else {
+					deletes += len(batch)
+				}
+			}
+			return ioutil.NopCloser(strings.NewReader(body)), nil
+		},
+	}
+
+	n, err := TruncateTable{"sessions"}.run(context.Background(), cl, []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 40 || deletes != 40 {
+		t.Error("expected ", 40)
+		t.Error("actual   ", n, deletes)
+	}
+
+	// keys only, and the second page starts after the first
+	if len(scans) != 2 || scans[0].ProjectionExpression != "id" || scans[1].ExclusiveStartKey["id"].N != "29" {
+		t.Error("actual   ", scans)
+	}
+}
+
+func TestTruncateTableRetries(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = 0
+
+	posts := 0
+	cl := MockClient{
+		OnPost: func(r Request) (io.ReadCloser, error) {
+			if _, ok := r.(*Scan); ok {
+				return ioutil.NopCloser(strings.NewReader(`{"Items": [{"id": {"S": "a"}}]}`)), nil
+			}
+			posts++
+			return ioutil.NopCloser(strings.NewReader(`{"UnprocessedItems": {"t": [{"DeleteRequest": {"Key": {"id": {"S": "a"}}}}]}}`)), nil
+		},
+	}
+
+	n, err := TruncateTable{"t"}.run(context.Background(), cl, []string{"id"})
+	if err == nil || n != 0 {
+		t.Error("expected error, got", n, err)
+	}
+	if posts != batchRetries+1 {
+		t.Error("expected ", batchRetries+1)
+		t.Error("actual   ", posts)
+	}
+}
+
+func TestTruncateTableCancel(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	posts := 0
+	cl := MockClient{
+		OnPost: func(r Request) (io.ReadCloser, error) {
+			if _, ok := r.(*Scan); ok {
+				return ioutil.NopCloser(strings.NewReader(`{"Items": [{"id": {"S": "a"}}]}`)), nil
+			}
+			posts++
+			cancel()
+			return ioutil.NopCloser(strings.NewReader(`{"UnprocessedItems": {"t": [{"DeleteRequest": {"Key": {"id": {"S": "a"}}}}]}}`)), nil
+		},
+	}
+
+	n, err := TruncateTable{"t"}.run(ctx, cl, []string{"id"})
+	if err != context.Canceled || n != 0 {
+		t.Error("expected canceled, got", n, err)
+	}
+	if posts != 1 {
+		t.Error("expected ", 1)
+		t.Error("actual   ", posts)
+	}
+}
@@ -5,15 +5,12 @@ import (
 	"io"
 )
 
-type Update struct {
-	Value  Attribute
-	Action string // PUT, ADD, DELETE
-}
-
 type UpdateItem struct {
-	TableName        string
-	Key              Item
-	AttributeUpdates map[string]Update
+	TableName           string
+	Key                 Item
+	UpdateExpression    string
+	ConditionExpression string `json:",omitempty"`
+	Expressions
 }
 
 func (u *UpdateItem) AddKey(exp Expression) {
@@ -25,13 +22,12 @@ func (u *UpdateItem) AddKey(exp Expression) {
 }
 
 func (u *UpdateItem) AddUpdate(exp Expression) {
-	if u.AttributeUpdates == nil {
-		u.AttributeUpdates = map[string]Update{}
-	}
+	set := u.Name(exp.Identifier) + " = " + u.Value(exp.Attribute())
 
-	u.AttributeUpdates[exp.Identifier] = Update{
-		Value:  exp.Attribute(),
-		Action: "PUT",
+	if u.UpdateExpression == "" {
+		u.UpdateExpression = "SET " + set
+	} else {
+		u.UpdateExpression += ", " + set
 	}
 }
 
@@ -0,0 +1,69 @@
+package dsql
+
+import (
+	"database/sql/driver"
+	"io"
+)
+
+type UpdateTable struct {
+	TableName                   string
+	AttributeDefinitions        []AttributeDefinition        `json:",omitempty"`
+	BillingMode                 string                       `json:",omitempty"`
+	ProvisionedThroughput       *Throughput                  `json:",omitempty"`
+	StreamSpecification         *StreamSpecification         `json:",omitempty"`
+	TableClass                  string                       `json:",omitempty"`
+	GlobalSecondaryIndexUpdates []GlobalSecondaryIndexUpdate `json:",omitempty"`
+
+	wait bool                     // until the indexes are active or gone
+	ttl  *TimeToLiveSpecification // sent with UpdateTimeToLive afterwards
+}
+
+// one of Create, Update or Delete is set
+type GlobalSecondaryIndexUpdate struct {
+	Create *GlobalSecondaryIndex       `json:",omitempty"`
+	Update *UpdateGlobalSecondaryIndex `json:",omitempty"`
+	Delete *DeleteGlobalSecondaryIndex `json:",omitempty"`
+}
+
+// a zero capacity is kept as it is
+type UpdateGlobalSecondaryIndex struct {
+	IndexName             string
+	ProvisionedThroughput Throughput
+}
+
+type DeleteGlobalSecondaryIndex struct {
+	IndexName string
+}
+
+// missing returns the key attributes of created indexes that have no
+// attribute definition
+func (u *UpdateTable) missing() (names []string) {
+	for _, update := range u.GlobalSecondaryIndexUpdates {
+		if update.Create == nil {
+			continue
+		}
+		for _, k := range update.Create.KeySchema {
+			defined := false
+			for _, def := range u.AttributeDefinitions {
+				if def.AttributeName == k.AttributeName {
+					defined = true
+				}
+			}
+			if !defined {
+				names = append(names, k.AttributeName)
+			}
+		}
+	}
+	return names
+}
+
+// empty tells if there is nothing but time to live to change
+func (u *UpdateTable) empty() bool {
+	return u.BillingMode == "" && u.ProvisionedThroughput == nil &&
+		u.StreamSpecification == nil && u.TableClass == "" &&
+		len(u.GlobalSecondaryIndexUpdates) == 0
+}
+
+func (u UpdateTable) Rows(body io.ReadCloser) (driver.Rows, error) {
+	return nil, nil
+}
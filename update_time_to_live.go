@@ -0,0 +1,21 @@
+package dsql
+
+import (
+	"database/sql/driver"
+	"io"
+)
+
+type UpdateTimeToLive struct {
+	TableName               string
+	TimeToLiveSpecification TimeToLiveSpecification
+}
+
+// disabling names the attribute that is currently used
+type TimeToLiveSpecification struct {
+	Enabled       bool
+	AttributeName string
+}
+
+func (u UpdateTimeToLive) Rows(body io.ReadCloser) (driver.Rows, error) {
+	return nil, nil
+}
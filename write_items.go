@@ -0,0 +1,181 @@
+package dsql
+
+import (
+	"sort"
+	"strings"
+)
+
+// UpdateItems is an UPDATE whose WHERE isn't a key lookup; the driver
+// queries or scans for the matching keys and updates the items one by one
+type UpdateItems struct {
+	UpdateItem
+	Where Expr
+}
+
+// DeleteItems is the same for DELETE
+type DeleteItems struct {
+	DeleteItem
+	Where Expr
+}
+
+// run returns how many items were updated, also on error
+func (u UpdateItems) run(cl Client, table *TableDescription) (int64, error) {
+	keys, err := matching(cl, table, u.Where)
+	if err != nil {
+		return 0, err
+	}
+
+	req := u.UpdateItem
+	req.ConditionExpression = recheck(&req.Expressions, table, u.Where)
+	return writeEach(cl, keys, func(key Item) Request {
+		item := req
+		item.Key = key
+		return item
+	})
+}
+
+func (d DeleteItems) run(cl Client, table *TableDescription) (int64, error) {
+	keys, err := matching(cl, table, d.Where)
+	if err != nil {
+		return 0, err
+	}
+
+	req := d.DeleteItem
+	req.ConditionExpression = recheck(&req.Expressions, table, d.Where)
+	return writeEach(cl, keys, func(key Item) Request {
+		item := req
+		item.Key = key
+		return item
+	})
+}
+
+// matching returns the keys of the items matching where, with a Query when
+// the conditions are joined by AND and fix the hash key and a Scan otherwise
+func matching(cl Client, table *TableDescription, where Expr) (keys []Item, err error) {
+	var (
+		req   Request
+		start *Item
+	)
+	if q := keyQuery(table, where); q != nil {
+		for _, k := range table.keys() {
+			q.AddColumn(k)
+		}
+		req, start = q, &q.ExclusiveStartKey
+	} else {
+		s := &Scan{TableName: table.TableName}
+		for _, k := range table.keys() {
+			s.AddColumn(k)
+		}
+		if where != nil {
+			s.FilterExpression = s.Predicate(where)
+		}
+		req, start = s, &s.ExclusiveStartKey
+	}
+
+	for {
+		body, err := cl.Post(req)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &QueryResponse{}
+		dec := ResponseDecoder{body, res}
+		err = dec.Decode()
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, res.Items...)
+		if res.LastEvaluatedKey == nil {
+			return keys, nil
+		}
+		*start = res.LastEvaluatedKey
+	}
+}
+
+// keyQuery puts the hash key and the first condition on the range key in
+// the key condition and the rest in the filter; nil if there is no hash key
+// or an OR
+func keyQuery(table *TableDescription, where Expr) *Query {
+	keys := table.keys()
+	conjs, exps := flatten(where)
+	hash := -1
+	for i, exp := range exps {
+		if conjs[i] != "" && strings.ToLower(conjs[i]) != "and" {
+			return nil
+		}
+		if hash < 0 && len(keys) > 0 && exp.Identifier == keys[0] && exp.Operator == "=" {
+			hash = i
+		}
+	}
+	if hash < 0 {
+		return nil
+	}
+
+	q := &Query{TableName: table.TableName, ScanIndexForward: true}
+	q.AddCondition("", exps[hash])
+	rng := -1
+	for i, exp := range exps {
+		switch {
+		case i == hash:
+		case rng < 0 && len(keys) > 1 && exp.Identifier == keys[1]:
+			rng = i
+			q.AddCondition("and", exp)
+		default:
+			q.FilterExpression = join(q.FilterExpression, " AND ", q.Condition(exp))
+		}
+	}
+	return q
+}
+
+// recheck is the condition of every write: the item still exists and still
+// matches where, so one changed or deleted since it was found is left alone
+func recheck(e *Expressions, table *TableDescription, where Expr) string {
+	cond := ""
+	if keys := table.keys(); len(keys) > 0 {
+		cond = "attribute_exists(" + e.Name(keys[0]) + ")"
+	}
+	if where == nil {
+		return cond
+	}
+	if _, ok := where.(Logical); ok && cond != "" {
+		return cond + " AND (" + e.Predicate(where) + ")"
+	}
+	return join(cond, " AND ", e.Predicate(where))
+}
+
+// writeEach posts a request per key and counts those whose condition held
+func writeEach(cl Client, keys []Item, req func(Item) Request) (n int64, err error) {
+	for _, key := range keys {
+		body, err := cl.Post(req(key))
+		switch {
+		case err == nil:
+			body.Close()
+			n++
+		case isError(err, "ConditionalCheckFailedException"):
+		default:
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// keyWhere turns a key back into the conditions it was made of
+func keyWhere(key Item) (where Expr) {
+	var names []string
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		exp := Expression{Identifier: name, Operator: "=", Value: key[name]}
+		if where == nil {
+			where = exp
+		} else {
+			where = Logical{"and", where, exp}
+		}
+	}
+	return where
+}
@@ -0,0 +1,59 @@
+package dsql
+
+import (
+	"testing"
+)
+
+var messagesTable = &TableDescription{
+	TableName: "messages",
+	KeySchema: []Schema{Schema{"group", "HASH"}, Schema{"id", "RANGE"}},
+}
+
+func TestKeyQuery(t *testing.T) {
+	stmt, err := Parse("delete from messages where author = 'a' and group = 'g' and id > 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := keyQuery(messagesTable, stmt.(*DeleteStmt).Where)
+	if q == nil {
+		t.Fatal("expected a query")
+	}
+	if q.KeyConditionExpression != "#n0 = :v0 AND id > :v2" || q.FilterExpression != "author = :v1" {
+		t.Error("actual   ", q.KeyConditionExpression, q.FilterExpression)
+	}
+
+	for _, source := range []string{
+		"delete from messages where id > 5",
+		"delete from messages where group = 'g' or id > 5",
+		"delete from messages where group > 'g'",
+	} {
+		stmt, err := Parse(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q := keyQuery(messagesTable, stmt.(*DeleteStmt).Where); q != nil {
+			t.Error("expected a scan for", source)
+		}
+	}
+}
+
+func TestRecheck(t *testing.T) {
+	for source, expected := range map[string]string{
+		"delete from messages where a = 1":                         "attribute_exists(#n0) AND a = :v0",
+		"delete from messages where a = 1 or b = 2":                "attribute_exists(#n0) AND (a = :v0 OR b = :v1)",
+		"delete from messages where a = 1 or b = 2 and c like 'x'": "attribute_exists(#n0) AND ((a = :v0 OR b = :v1) AND begins_with(c, :v2))",
+	} {
+		stmt, err := Parse(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e := &Expressions{}
+		actual := recheck(e, messagesTable, stmt.(*DeleteStmt).Where)
+		if actual != expected {
+			t.Error("expected ", expected)
+			t.Error("actual   ", actual)
+		}
+	}
+}